@@ -0,0 +1,104 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const defaultAzureAPIVersion = "2024-02-01"
+
+// ErrNoAzureDeployment is returned for requests without a model when AzureConfig.DefaultDeployment is not set.
+var ErrNoAzureDeployment = errors.New("openai: no Azure deployment for the request, set AzureConfig.DefaultDeployment")
+
+// TokenProvider returns Microsoft Entra ID access tokens for Azure OpenAI.
+// Implementations are responsible for caching and refreshing tokens.
+type TokenProvider interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// AzureConfig configures the Client for Azure OpenAI.
+type AzureConfig struct {
+	// Endpoint is the resource endpoint, e.g. "https://my-resource.openai.azure.com".
+	Endpoint string
+	// APIVersion is the value of the api-version query parameter. Defaults to "2024-02-01".
+	APIVersion string
+	// Deployments maps model names to deployment names.
+	// Models without an entry are assumed to be deployed under their own name.
+	Deployments map[string]string
+	// DefaultDeployment is used for requests without a model, e.g. image generation.
+	DefaultDeployment string
+	// TokenProvider, if set, authenticates requests with Entra ID bearer tokens instead of the API key.
+	TokenProvider TokenProvider
+}
+
+// deploymentOperations are the operations served per deployment rather than per resource.
+var deploymentOperations = map[string]bool{
+	OperationCompletions:         true,
+	OperationChatCompletions:     true,
+	OperationEdits:               true,
+	OperationImagesGenerate:      true,
+	OperationImagesEdit:          true,
+	OperationImagesVariation:     true,
+	OperationEmbeddings:          true,
+	OperationAudioTranscriptions: true,
+	OperationAudioTranslations:   true,
+}
+
+// WithAzure sends the requests to Azure OpenAI.
+// Paths are rewritten to the deployment of the requested model,
+// and the API key is sent in the api-key header instead of the Authorization one.
+func WithAzure(config AzureConfig) ClientOption {
+	return func(c *Client) {
+		if config.APIVersion == "" {
+			config.APIVersion = defaultAzureAPIVersion
+		}
+
+		c.azure = &config
+		c.baseURL = strings.TrimRight(config.Endpoint, "/") + "/openai"
+	}
+}
+
+// deployment returns the deployment serving the model.
+func (a *AzureConfig) deployment(model string) string {
+	if model == "" {
+		return a.DefaultDeployment
+	}
+
+	if deployment, ok := a.Deployments[model]; ok {
+		return deployment
+	}
+
+	return model
+}
+
+func (a *AzureConfig) url(baseURL string, op Operation, path string) (string, error) {
+	if deploymentOperations[op.Name] {
+		deployment := a.deployment(op.Model)
+		if deployment == "" {
+			return "", ErrNoAzureDeployment
+		}
+
+		path = "/deployments/" + url.PathEscape(deployment) + path
+	}
+
+	separator := "?"
+	if strings.Contains(baseURL+path, "?") {
+		separator = "&"
+	}
+
+	return baseURL + path + separator + "api-version=" + url.QueryEscape(a.APIVersion), nil
+}
+
+func (a *AzureConfig) authorizeToken(req *http.Request) error {
+	token, err := a.TokenProvider.Token(req.Context())
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type staticTokenProvider string
+
+func (p staticTokenProvider) Token(context.Context) (string, error) {
+	return string(p), nil
+}
+
+func TestClient_WithAzure(t *testing.T) {
+	t.Parallel()
+
+	var gotPath, gotVersion, gotAPIKey, gotAuthorization string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotVersion = r.URL.Query().Get("api-version")
+		gotAPIKey = r.Header.Get("api-key")
+		gotAuthorization = r.Header.Get("Authorization")
+
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		name              string
+		config            AzureConfig
+		call              func(client *Client) error
+		wantPath          string
+		wantAPIKey        string
+		wantAuthorization string
+	}{
+		{
+			name: "mapped deployment",
+			config: AzureConfig{
+				Endpoint:    server.URL + "/",
+				Deployments: map[string]string{"gpt-4": "prod-gpt4"},
+			},
+			call: func(client *Client) error {
+				_, err := client.ChatCompletion(context.Background(), ChatCompletionRequest{Model: "gpt-4"})
+				return err
+			},
+			wantPath:   "/openai/deployments/prod-gpt4/chat/completions",
+			wantAPIKey: "key",
+		},
+		{
+			name:   "resource operation",
+			config: AzureConfig{Endpoint: server.URL},
+			call: func(client *Client) error {
+				_, err := client.FineTunes(context.Background())
+				return err
+			},
+			wantPath:   "/openai/fine-tunes",
+			wantAPIKey: "key",
+		},
+		{
+			name: "entra id token",
+			config: AzureConfig{
+				Endpoint:          server.URL,
+				DefaultDeployment: "dalle",
+				TokenProvider:     staticTokenProvider("token"),
+			},
+			call: func(client *Client) error {
+				_, err := client.Image(context.Background(), ImageRequest{Prompt: "cat"})
+				return err
+			},
+			wantPath:          "/openai/deployments/dalle/images/generations",
+			wantAuthorization: "Bearer token",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := NewClient("key", WithAzure(tc.config))
+
+			if err := tc.call(client); err != nil {
+				t.Fatal(err)
+			}
+
+			if gotPath != tc.wantPath {
+				t.Errorf("expected path to be %s, got %s", tc.wantPath, gotPath)
+			}
+			if gotVersion != defaultAzureAPIVersion {
+				t.Errorf("expected api-version to be %s, got %s", defaultAzureAPIVersion, gotVersion)
+			}
+			if gotAPIKey != tc.wantAPIKey {
+				t.Errorf("expected api-key header to be %q, got %q", tc.wantAPIKey, gotAPIKey)
+			}
+			if gotAuthorization != tc.wantAuthorization {
+				t.Errorf("expected Authorization header to be %q, got %q", tc.wantAuthorization, gotAuthorization)
+			}
+		})
+	}
+}
+
+func TestAzureConfig_URL(t *testing.T) {
+	t.Parallel()
+
+	config := AzureConfig{APIVersion: defaultAzureAPIVersion}
+
+	tests := []struct {
+		name    string
+		baseURL string
+		op      Operation
+		path    string
+		want    string
+		wantErr error
+	}{
+		{
+			name:    "deployment",
+			baseURL: "https://example.com/openai",
+			op:      Operation{Name: OperationEmbeddings, Model: "ada"},
+			path:    "/embeddings",
+			want:    "https://example.com/openai/deployments/ada/embeddings?api-version=" + defaultAzureAPIVersion,
+		},
+		{
+			name:    "existing query",
+			baseURL: "https://example.com/openai",
+			op:      Operation{Name: OperationFilesList},
+			path:    "/files?purpose=fine-tune",
+			want:    "https://example.com/openai/files?purpose=fine-tune&api-version=" + defaultAzureAPIVersion,
+		},
+		{
+			name:    "no deployment",
+			baseURL: "https://example.com/openai",
+			op:      Operation{Name: OperationImagesGenerate},
+			path:    "/images/generations",
+			wantErr: ErrNoAzureDeployment,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := config.url(tc.baseURL, tc.op, tc.path)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("expected error %v, got %v", tc.wantErr, err)
+			}
+			if got != tc.want {
+				t.Errorf("expected URL to be %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package openai
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const defaultEjectionCooldown = 30 * time.Second
+
+// Backend is an OpenAI-compatible API endpoint with its credentials.
+type Backend struct {
+	BaseURL string
+	// APIKey authenticates requests to the backend. If empty, the Client credentials are used.
+	APIKey       string
+	Organization string
+}
+
+type BalancingStrategy int
+
+const (
+	// BalancingRoundRobin sends requests to the backends in turn.
+	BalancingRoundRobin BalancingStrategy = iota
+	// BalancingLeastOutstanding sends requests to the backend with the fewest requests in flight.
+	BalancingLeastOutstanding
+)
+
+type BalancerOption func(*Balancer)
+
+// WithBalancingStrategy sets how backends are picked. Defaults to BalancingRoundRobin.
+func WithBalancingStrategy(strategy BalancingStrategy) BalancerOption {
+	return func(b *Balancer) {
+		b.strategy = strategy
+	}
+}
+
+// WithEjectionCooldown sets how long a backend returning 429 or 5xx is skipped. Defaults to 30 seconds.
+func WithEjectionCooldown(cooldown time.Duration) BalancerOption {
+	return func(b *Balancer) {
+		b.cooldown = cooldown
+	}
+}
+
+// Balancer distributes requests across a pool of backends.
+// Backends failing with 429, 5xx or a network error are ejected for a cooldown period,
+// and idempotent requests fail over to the next healthy backend.
+// Requests are idempotent if their method is GET, HEAD or DELETE, or they have an Idempotency-Key header.
+type Balancer struct {
+	backends []*backendState
+	strategy BalancingStrategy
+	cooldown time.Duration
+	next     atomic.Uint64
+	now      func() time.Time
+}
+
+type backendState struct {
+	Backend
+
+	outstanding atomic.Int64
+
+	mu           sync.Mutex
+	ejectedUntil time.Time
+}
+
+// NewBalancer creates a Balancer over the given backends.
+func NewBalancer(backends []Backend, options ...BalancerOption) *Balancer {
+	balancer := &Balancer{
+		strategy: BalancingRoundRobin,
+		cooldown: defaultEjectionCooldown,
+		now:      time.Now,
+	}
+
+	for _, backend := range backends {
+		backend.BaseURL = strings.TrimRight(backend.BaseURL, "/")
+		balancer.backends = append(balancer.backends, &backendState{Backend: backend})
+	}
+
+	for _, option := range options {
+		option(balancer)
+	}
+
+	return balancer
+}
+
+// WithBalancer distributes the requests of the Client across the balancer backends.
+// Requests with a base URL set by WithRequestBaseURL are sent as is.
+func WithBalancer(balancer *Balancer) ClientOption {
+	return func(c *Client) {
+		c.balancer = balancer
+	}
+}
+
+// do sends the request to a backend, failing over to the other ones if the request is idempotent.
+// The request URL must start with baseURL, which is replaced by the backend one.
+func (b *Balancer) do(req *http.Request, baseURL string, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	if len(b.backends) == 0 {
+		return send(req)
+	}
+
+	relative := strings.TrimPrefix(req.URL.String(), baseURL)
+	tried := make(map[*backendState]bool)
+
+	for {
+		backend := b.pick(tried)
+		tried[backend] = true
+
+		backendReq, err := rewindRequest(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if backendReq.URL, err = url.Parse(backend.BaseURL + relative); err != nil {
+			return nil, err
+		}
+		backendReq.Host = ""
+
+		if backend.APIKey != "" {
+			backendReq.Header.Set("Authorization", "Bearer "+backend.APIKey)
+			backendReq.Header.Del("OpenAI-Organization")
+		}
+		if backend.Organization != "" {
+			backendReq.Header.Set("OpenAI-Organization", backend.Organization)
+		}
+
+		backend.outstanding.Add(1)
+		resp, err := send(backendReq)
+		backend.outstanding.Add(-1)
+
+		if (err == nil && !isRetryableStatus(resp.StatusCode)) || req.Context().Err() != nil {
+			return resp, err
+		}
+
+		b.eject(backend)
+
+		if !isIdempotent(req) || len(tried) == len(b.backends) {
+			return resp, err
+		}
+
+		if resp != nil {
+			resp.Body.Close()
+		}
+	}
+}
+
+// pick returns the next healthy backend not tried yet.
+// If all of them are ejected, the one whose cooldown ends first is returned.
+func (b *Balancer) pick(tried map[*backendState]bool) *backendState {
+	var (
+		now      = b.now()
+		healthy  []*backendState
+		fallback *backendState
+		earliest time.Time
+	)
+
+	for _, backend := range b.backends {
+		if tried[backend] {
+			continue
+		}
+
+		backend.mu.Lock()
+		ejectedUntil := backend.ejectedUntil
+		backend.mu.Unlock()
+
+		if !now.Before(ejectedUntil) {
+			healthy = append(healthy, backend)
+		} else if fallback == nil || ejectedUntil.Before(earliest) {
+			fallback, earliest = backend, ejectedUntil
+		}
+	}
+
+	if len(healthy) == 0 {
+		return fallback
+	}
+
+	if b.strategy == BalancingLeastOutstanding {
+		best := healthy[0]
+		for _, backend := range healthy[1:] {
+			if backend.outstanding.Load() < best.outstanding.Load() {
+				best = backend
+			}
+		}
+		return best
+	}
+
+	return healthy[int((b.next.Add(1)-1)%uint64(len(healthy)))]
+}
+
+func (b *Balancer) eject(backend *backendState) {
+	backend.mu.Lock()
+	backend.ejectedUntil = b.now().Add(b.cooldown)
+	backend.mu.Unlock()
+}
+
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodDelete:
+		return true
+	default:
+		return req.Header.Get("Idempotency-Key") != ""
+	}
+}
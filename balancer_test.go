@@ -0,0 +1,55 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestClient_WithBalancer(t *testing.T) {
+	t.Parallel()
+
+	var unhealthyCalls, healthyCalls atomic.Int32
+
+	unhealthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		unhealthyCalls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(unhealthy.Close)
+
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		healthyCalls.Add(1)
+
+		if got, want := r.Header.Get("Authorization"), "Bearer gateway-key"; got != want {
+			t.Errorf("expected Authorization header to be %s, got %s", want, got)
+		}
+		if got, want := r.URL.Path, "/v1/models"; got != want {
+			t.Errorf("expected path to be %s, got %s", want, got)
+		}
+
+		_, _ = w.Write([]byte(`{"object":"list"}`))
+	}))
+	t.Cleanup(healthy.Close)
+
+	balancer := NewBalancer([]Backend{
+		{BaseURL: unhealthy.URL + "/v1", APIKey: "openai-key"},
+		{BaseURL: healthy.URL + "/v1/", APIKey: "gateway-key"},
+	})
+
+	client := NewClient("test", WithBalancer(balancer))
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.Models(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := unhealthyCalls.Load(); got != 1 {
+		t.Fatalf("expected the unhealthy backend to be ejected after 1 call, got %d calls", got)
+	}
+	if got := healthyCalls.Load(); got != 3 {
+		t.Fatalf("expected 3 calls to the healthy backend, got %d", got)
+	}
+}
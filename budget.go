@@ -0,0 +1,434 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/KirillMironov/openai/internal/tokens"
+)
+
+// defaultBudgetCompletionTokens is the number of completion tokens reserved for every choice of requests without MaxTokens.
+const defaultBudgetCompletionTokens = 1024
+
+// ErrBudgetExceeded is returned, wrapped in a *BudgetExceededError, when a request would exceed a budget limit.
+var ErrBudgetExceeded = errors.New("openai: budget exceeded")
+
+// BudgetExceededError describes the budget limit a rejected request would have exceeded.
+type BudgetExceededError struct {
+	Limit     BudgetLimit
+	User      string
+	Spent     BudgetUsage
+	Estimated BudgetUsage
+}
+
+func (e *BudgetExceededError) Error() string {
+	scope := "global"
+	if e.Limit.PerUser {
+		scope = fmt.Sprintf("user %q", e.User)
+	}
+
+	return fmt.Sprintf("openai: %s %s budget exceeded: spent $%.4f and %d tokens, request estimated at $%.4f and %d tokens",
+		scope, e.Limit.Period, e.Spent.USD, e.Spent.Tokens, e.Estimated.USD, e.Estimated.Tokens)
+}
+
+func (e *BudgetExceededError) Unwrap() error {
+	return ErrBudgetExceeded
+}
+
+type BudgetPeriod string
+
+const (
+	BudgetPeriodDaily   BudgetPeriod = "daily"
+	BudgetPeriodMonthly BudgetPeriod = "monthly"
+)
+
+// key returns the identifier of the period containing t, e.g. "2023-05-17" for a daily period.
+func (p BudgetPeriod) key(t time.Time) string {
+	t = t.UTC()
+
+	if p == BudgetPeriodMonthly {
+		return t.Format("2006-01")
+	}
+
+	return t.Format("2006-01-02")
+}
+
+// end returns the end of the period containing t.
+func (p BudgetPeriod) end(t time.Time) time.Time {
+	t = t.UTC()
+
+	if p == BudgetPeriodMonthly {
+		return time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	return time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+}
+
+// BudgetLimit caps the spending over a period.
+// A zero USD or Tokens value means the corresponding cap is not enforced.
+type BudgetLimit struct {
+	Period BudgetPeriod
+	USD    float64
+	Tokens int64
+	// PerUser applies the limit to every end-user identifier separately instead of to all requests together.
+	PerUser bool
+}
+
+// BudgetUsage is the spending recorded against a budget limit.
+type BudgetUsage struct {
+	USD    float64 `json:"usd"`
+	Tokens int64   `json:"tokens"`
+}
+
+func (u BudgetUsage) add(other BudgetUsage) BudgetUsage {
+	return BudgetUsage{USD: u.USD + other.USD, Tokens: u.Tokens + other.Tokens}
+}
+
+func (u BudgetUsage) negate() BudgetUsage {
+	return BudgetUsage{USD: -u.USD, Tokens: -u.Tokens}
+}
+
+// BudgetStore persists budget counters.
+// Implementations must be safe for concurrent use.
+type BudgetStore interface {
+	// Add adds the usage to the counter with the given key and returns the new value.
+	// The counter belongs to a period ending at expiresAt, after which it may be deleted.
+	Add(ctx context.Context, key string, usage BudgetUsage, expiresAt time.Time) (BudgetUsage, error)
+}
+
+// budgetCounters holds counters, deleting them once their period ended.
+type budgetCounters struct {
+	counters map[string]budgetCounter
+	// pruneAt is the earliest expiry of the counters, when expired ones are deleted.
+	pruneAt time.Time
+}
+
+type budgetCounter struct {
+	BudgetUsage
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func newBudgetCounters() budgetCounters {
+	return budgetCounters{counters: make(map[string]budgetCounter)}
+}
+
+func (c *budgetCounters) add(key string, usage BudgetUsage, expiresAt, now time.Time) BudgetUsage {
+	if !c.pruneAt.IsZero() && !now.Before(c.pruneAt) {
+		c.prune(now)
+	}
+
+	counter := c.counters[key]
+	counter.BudgetUsage = counter.BudgetUsage.add(usage)
+	counter.ExpiresAt = expiresAt
+	c.counters[key] = counter
+
+	if c.pruneAt.IsZero() || expiresAt.Before(c.pruneAt) {
+		c.pruneAt = expiresAt
+	}
+
+	return counter.BudgetUsage
+}
+
+// prune deletes the expired counters.
+func (c *budgetCounters) prune(now time.Time) {
+	c.pruneAt = time.Time{}
+
+	for key, counter := range c.counters {
+		switch {
+		case !now.Before(counter.ExpiresAt):
+			delete(c.counters, key)
+		case c.pruneAt.IsZero() || counter.ExpiresAt.Before(c.pruneAt):
+			c.pruneAt = counter.ExpiresAt
+		}
+	}
+}
+
+// MemoryBudgetStore keeps budget counters in memory. Counters of ended periods are deleted.
+type MemoryBudgetStore struct {
+	now func() time.Time
+
+	mu       sync.Mutex
+	counters budgetCounters
+}
+
+func NewMemoryBudgetStore() *MemoryBudgetStore {
+	return &MemoryBudgetStore{now: time.Now, counters: newBudgetCounters()}
+}
+
+func (s *MemoryBudgetStore) Add(_ context.Context, key string, usage BudgetUsage, expiresAt time.Time) (BudgetUsage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.counters.add(key, usage, expiresAt, s.now()), nil
+}
+
+// FileBudgetStore keeps budget counters in memory and persists them to a JSON file on every change,
+// so counters survive restarts. Counters of ended periods are deleted.
+type FileBudgetStore struct {
+	path string
+	now  func() time.Time
+
+	mu       sync.Mutex
+	counters budgetCounters
+}
+
+// NewFileBudgetStore creates a FileBudgetStore loading the counters from the file at path, if it exists.
+func NewFileBudgetStore(path string) (*FileBudgetStore, error) {
+	store := &FileBudgetStore{
+		path:     path,
+		now:      time.Now,
+		counters: newBudgetCounters(),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(data, &store.counters.counters); err != nil {
+		return nil, fmt.Errorf("openai: invalid budget file %s: %w", path, err)
+	}
+
+	store.counters.prune(store.now())
+
+	return store, nil
+}
+
+func (s *FileBudgetStore) Add(_ context.Context, key string, usage BudgetUsage, expiresAt time.Time) (BudgetUsage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.counters.counters[key]
+	spent := s.counters.add(key, usage, expiresAt, s.now())
+
+	if err := s.save(); err != nil {
+		if existed {
+			s.counters.counters[key] = previous
+		} else {
+			delete(s.counters.counters, key)
+		}
+
+		return previous.BudgetUsage, err
+	}
+
+	return spent, nil
+}
+
+// save atomically replaces the file with the current counters.
+func (s *FileBudgetStore) save() error {
+	data, err := json.Marshal(s.counters.counters)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err = file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err = file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), s.path)
+}
+
+// Budget rejects requests that would exceed its spending limits.
+// The cost of a request is estimated from its prompt and MaxTokens before it is sent,
+// and the estimate is replaced with the actual usage once the response arrives.
+// Completions without MaxTokens are estimated at 1024 completion tokens per choice.
+type Budget struct {
+	prices PriceTable
+	store  BudgetStore
+	limits []BudgetLimit
+	now    func() time.Time
+}
+
+// NewBudget creates a Budget pricing requests with the given table and keeping counters in the store.
+func NewBudget(prices PriceTable, store BudgetStore, limits ...BudgetLimit) *Budget {
+	return &Budget{
+		prices: prices,
+		store:  store,
+		limits: limits,
+		now:    time.Now,
+	}
+}
+
+// WithBudget rejects requests exceeding the budget with ErrBudgetExceeded.
+func WithBudget(budget *Budget) ClientOption {
+	return WithMiddleware(BudgetMiddleware(budget))
+}
+
+// BudgetMiddleware rejects requests exceeding the budget with ErrBudgetExceeded
+// and records the usage of the sent ones. Responses shared by a Deduplicator with other callers are recorded once.
+func BudgetMiddleware(budget *Budget) Middleware {
+	return func(next Handler) Handler {
+		return func(op Operation, req *http.Request) (*http.Response, error) {
+			ctx := req.Context()
+			keys := budget.keys(op.User)
+			estimate := budget.estimate(op)
+
+			if err := budget.reserve(ctx, keys, op.User, estimate); err != nil {
+				return nil, err
+			}
+
+			resp, err := next(op, req)
+			if err != nil || resp.StatusCode != http.StatusOK || sharedResponse(resp) {
+				budget.settle(ctx, keys, estimate, BudgetUsage{})
+				return resp, err
+			}
+
+			body, err := readResponseBody(resp)
+			if err != nil {
+				budget.settle(ctx, keys, estimate, BudgetUsage{})
+				return nil, err
+			}
+
+			usage, _ := usageOf(body)
+
+			budget.settle(ctx, keys, estimate, BudgetUsage{
+				USD:    budget.prices.Cost(op.Model, usage),
+				Tokens: int64(usage.TotalTokens),
+			})
+
+			return resp, nil
+		}
+	}
+}
+
+// budgetKey identifies the counter of a limit for a period.
+type budgetKey struct {
+	name      string
+	expiresAt time.Time
+}
+
+// keys returns the counter keys of the limits for the current periods.
+func (b *Budget) keys(user string) []budgetKey {
+	now := b.now()
+	keys := make([]budgetKey, len(b.limits))
+
+	for i, limit := range b.limits {
+		scope := "global"
+		if limit.PerUser {
+			scope = "user:" + user
+		}
+
+		keys[i] = budgetKey{
+			name:      fmt.Sprintf("%s:%s:%s", scope, limit.Period, limit.Period.key(now)),
+			expiresAt: limit.Period.end(now),
+		}
+	}
+
+	return keys
+}
+
+// reserve adds the estimate to every counter, rolling back if any limit is exceeded.
+func (b *Budget) reserve(ctx context.Context, keys []budgetKey, user string, estimate BudgetUsage) error {
+	for i, limit := range b.limits {
+		spent, err := b.store.Add(ctx, keys[i].name, estimate, keys[i].expiresAt)
+		if err != nil {
+			b.settle(ctx, keys[:i], estimate, BudgetUsage{})
+			return err
+		}
+
+		if (limit.USD > 0 && spent.USD > limit.USD) || (limit.Tokens > 0 && spent.Tokens > limit.Tokens) {
+			b.settle(ctx, keys[:i+1], estimate, BudgetUsage{})
+
+			return &BudgetExceededError{
+				Limit:     limit,
+				User:      user,
+				Spent:     spent.add(estimate.negate()),
+				Estimated: estimate,
+			}
+		}
+	}
+
+	return nil
+}
+
+// settle replaces the reserved estimate with the actual usage.
+// Store errors are ignored, as the request has already been sent.
+// The caller cancellation is ignored too, otherwise the estimate would stay reserved forever.
+func (b *Budget) settle(ctx context.Context, keys []budgetKey, estimate, actual BudgetUsage) {
+	ctx = context.WithoutCancel(ctx)
+	delta := actual.add(estimate.negate())
+
+	for _, key := range keys {
+		_, _ = b.store.Add(ctx, key.name, delta, key.expiresAt)
+	}
+}
+
+// estimate returns the worst-case usage of the request:
+// the estimated prompt tokens plus MaxTokens completion tokens for every generated choice.
+func (b *Budget) estimate(op Operation) BudgetUsage {
+	var (
+		prompt     []string
+		maxTokens  int
+		numChoices = 1
+	)
+
+	switch payload := op.Payload.(type) {
+	case ChatCompletionRequest:
+		for _, message := range payload.Messages {
+			prompt = append(prompt, message.Content)
+			for _, part := range message.MultiContent {
+				prompt = append(prompt, part.Text)
+			}
+		}
+		maxTokens = completionTokens(payload.MaxTokens)
+		if payload.N != nil {
+			numChoices = *payload.N
+		}
+	case CompletionRequest:
+		prompt = payload.Prompt
+		maxTokens = completionTokens(payload.MaxTokens)
+		if payload.N != nil {
+			numChoices = *payload.N
+		}
+		// The API generates BestOf choices and returns the N best ones.
+		numChoices = max(numChoices, payload.BestOf)
+	case EditRequest:
+		prompt = []string{payload.Input, payload.Instruction}
+	case EmbeddingRequest:
+		prompt = payload.Input
+	}
+
+	var usage Usage
+
+	for _, text := range prompt {
+		usage.PromptTokens += tokens.Estimate(text)
+	}
+
+	usage.CompletionTokens = maxTokens * numChoices
+	usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+
+	return BudgetUsage{
+		USD:    b.prices.Cost(op.Model, usage),
+		Tokens: int64(usage.TotalTokens),
+	}
+}
+
+// completionTokens returns the number of completion tokens to reserve for every choice.
+func completionTokens(maxTokens int) int {
+	if maxTokens > 0 {
+		return maxTokens
+	}
+
+	return defaultBudgetCompletionTokens
+}
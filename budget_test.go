@@ -0,0 +1,175 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_WithBudget(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		_, _ = w.Write([]byte(`{"usage":{"prompt_tokens":10,"completion_tokens":90,"total_tokens":100}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	store, err := NewFileBudgetStore(filepath.Join(t.TempDir(), "budget.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	budget := NewBudget(PriceTable{"gpt-4": {Prompt: 0.03, Completion: 0.06}}, store,
+		BudgetLimit{Period: BudgetPeriodDaily, Tokens: 150},
+		BudgetLimit{Period: BudgetPeriodMonthly, USD: 10, PerUser: true},
+	)
+
+	client := NewClient("test", WithBaseURL(server.URL), WithBudget(budget))
+
+	request := ChatCompletionRequest{Model: "gpt-4", MaxTokens: 60, User: "alice"}
+
+	if _, err = client.ChatCompletion(context.Background(), request); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.ChatCompletion(context.Background(), request)
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("expected budget exceeded error, got %v", err)
+	}
+
+	var budgetErr *BudgetExceededError
+	if !errors.As(err, &budgetErr) || budgetErr.Spent.Tokens != 100 {
+		t.Fatalf("expected 100 spent tokens, got %v", err)
+	}
+
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("expected 1 call to reach the server, got %d", got)
+	}
+
+	reloaded, err := NewFileBudgetStore(store.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := budget.keys("alice")[1]
+
+	spent, err := reloaded.Add(context.Background(), key.name, BudgetUsage{}, key.expiresAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 0.0057; spent.USD < want-1e-9 || spent.USD > want+1e-9 {
+		t.Fatalf("expected persisted user spending to be $%v, got $%v", want, spent.USD)
+	}
+}
+
+// contextBudgetStore fails like remote stores do once the context is done.
+type contextBudgetStore struct {
+	*MemoryBudgetStore
+}
+
+func (s contextBudgetStore) Add(ctx context.Context, key string, usage BudgetUsage, expiresAt time.Time) (BudgetUsage, error) {
+	if err := ctx.Err(); err != nil {
+		return BudgetUsage{}, err
+	}
+
+	return s.MemoryBudgetStore.Add(ctx, key, usage, expiresAt)
+}
+
+func TestBudgetMiddleware_Cancel(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+
+		cancel()
+		<-r.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+
+	store := contextBudgetStore{NewMemoryBudgetStore()}
+	budget := NewBudget(PriceTable{}, store, BudgetLimit{Period: BudgetPeriodDaily, Tokens: 1000})
+
+	client := NewClient("test", WithBaseURL(server.URL), WithBudget(budget))
+
+	if _, err := client.ChatCompletion(ctx, ChatCompletionRequest{Model: "gpt-4", MaxTokens: 100}); err == nil {
+		t.Fatal("expected error")
+	}
+
+	key := budget.keys("")[0]
+
+	spent, err := store.Add(context.Background(), key.name, BudgetUsage{}, key.expiresAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spent.Tokens != 0 {
+		t.Fatalf("expected the estimate to be released, got %d reserved tokens", spent.Tokens)
+	}
+}
+
+func TestMemoryBudgetStore_Expiry(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx   = context.Background()
+		now   = time.Date(2023, 5, 17, 12, 0, 0, 0, time.UTC)
+		store = NewMemoryBudgetStore()
+	)
+
+	store.now = func() time.Time { return now }
+
+	if _, err := store.Add(ctx, "global:daily:2023-05-17", BudgetUsage{Tokens: 10}, BudgetPeriodDaily.end(now)); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(24 * time.Hour)
+
+	if _, err := store.Add(ctx, "global:daily:2023-05-18", BudgetUsage{Tokens: 20}, BudgetPeriodDaily.end(now)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := store.counters.counters["global:daily:2023-05-17"]; ok {
+		t.Fatal("expected the counter of the ended period to be deleted")
+	}
+	if got := len(store.counters.counters); got != 1 {
+		t.Fatalf("expected 1 counter, got %d", got)
+	}
+}
+
+func TestBudget_Estimate(t *testing.T) {
+	t.Parallel()
+
+	budget := NewBudget(PriceTable{}, NewMemoryBudgetStore())
+
+	tests := []struct {
+		name    string
+		payload any
+		want    int64
+	}{
+		{name: "max tokens", payload: ChatCompletionRequest{MaxTokens: 100, N: Int(2)}, want: 200},
+		{name: "default max tokens", payload: ChatCompletionRequest{}, want: defaultBudgetCompletionTokens},
+		{name: "best of", payload: CompletionRequest{MaxTokens: 100, N: Int(2), BestOf: 5}, want: 500},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := budget.estimate(Operation{Payload: tc.payload}).Tokens; got != tc.want {
+				t.Fatalf("expected %d tokens, got %d", tc.want, got)
+			}
+		})
+	}
+}
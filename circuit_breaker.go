@@ -0,0 +1,251 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without calling the API while the circuit of the operation and model is open.
+var ErrCircuitOpen = errors.New("openai: circuit breaker is open")
+
+type CircuitState int
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitOpen
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("CircuitState(%d)", int(s))
+	}
+}
+
+// CircuitKey identifies a circuit. Every operation and model pair has its own circuit.
+type CircuitKey struct {
+	Operation string
+	Model     string
+}
+
+// CircuitBreakerConfig configures a CircuitBreaker. Zero values are replaced by the defaults.
+type CircuitBreakerConfig struct {
+	// FailureRate is the share of failed calls in the window opening the circuit. Defaults to 0.5.
+	FailureRate float64
+	// MinRequests is the number of calls in the window required before the failure rate is evaluated.
+	// Defaults to 10.
+	MinRequests int
+	// Window is the period over which calls are counted. Defaults to 1 minute.
+	Window time.Duration
+	// SlowCallDuration, if set, counts calls taking longer as failed.
+	SlowCallDuration time.Duration
+	// OpenDuration is how long the circuit stays open before probe calls are let through. Defaults to 30 seconds.
+	OpenDuration time.Duration
+	// HalfOpenRequests is the number of probe calls which must succeed to close the circuit. Defaults to 1.
+	HalfOpenRequests int
+	// OnStateChange, if set, is called on every state transition.
+	OnStateChange func(key CircuitKey, from, to CircuitState)
+}
+
+// CircuitBreaker fails calls fast with ErrCircuitOpen after the API started failing,
+// instead of letting them wait for timeouts.
+// Network errors, rate limiting (429), server errors (5xx) and slow calls count as failures.
+type CircuitBreaker struct {
+	config CircuitBreakerConfig
+	now    func() time.Time
+
+	mu       sync.Mutex
+	circuits map[CircuitKey]*circuit
+}
+
+type circuit struct {
+	state       CircuitState
+	windowStart time.Time
+	requests    int
+	failures    int
+	openedAt    time.Time
+	probes      int
+	successes   int
+}
+
+// NewCircuitBreaker creates a CircuitBreaker with the given configuration.
+func NewCircuitBreaker(config CircuitBreakerConfig) *CircuitBreaker {
+	if config.FailureRate <= 0 {
+		config.FailureRate = 0.5
+	}
+	if config.MinRequests <= 0 {
+		config.MinRequests = 10
+	}
+	if config.Window <= 0 {
+		config.Window = time.Minute
+	}
+	if config.OpenDuration <= 0 {
+		config.OpenDuration = 30 * time.Second
+	}
+	if config.HalfOpenRequests <= 0 {
+		config.HalfOpenRequests = 1
+	}
+
+	return &CircuitBreaker{
+		config:   config,
+		now:      time.Now,
+		circuits: make(map[CircuitKey]*circuit),
+	}
+}
+
+// WithCircuitBreaker guards every API call with the circuit breaker.
+func WithCircuitBreaker(breaker *CircuitBreaker) ClientOption {
+	return WithMiddleware(CircuitBreakerMiddleware(breaker))
+}
+
+// CircuitBreakerMiddleware guards every API call with the circuit breaker.
+func CircuitBreakerMiddleware(breaker *CircuitBreaker) Middleware {
+	return func(next Handler) Handler {
+		return func(op Operation, req *http.Request) (*http.Response, error) {
+			key := CircuitKey{Operation: op.Name, Model: op.Model}
+
+			if !breaker.allow(key) {
+				return nil, fmt.Errorf("%w: %s %s", ErrCircuitOpen, op.Name, op.Model)
+			}
+
+			start := time.Now()
+
+			resp, err := next(op, req)
+
+			// Calls abandoned by the caller say nothing about the API health.
+			if err != nil && errors.Is(req.Context().Err(), context.Canceled) {
+				breaker.release(key)
+				return resp, err
+			}
+
+			failed := err != nil || isRetryableStatus(resp.StatusCode) ||
+				(breaker.config.SlowCallDuration > 0 && time.Since(start) > breaker.config.SlowCallDuration)
+
+			breaker.record(key, failed)
+
+			return resp, err
+		}
+	}
+}
+
+// State returns the current state of the circuit of the operation and model.
+func (b *CircuitBreaker) State(operation, model string) CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if c, ok := b.circuits[CircuitKey{Operation: operation, Model: model}]; ok {
+		return c.state
+	}
+
+	return CircuitClosed
+}
+
+// allow reports whether a call may be made, moving an open circuit to half-open once its open duration passed.
+func (b *CircuitBreaker) allow(key CircuitKey) bool {
+	b.mu.Lock()
+
+	c := b.circuit(key)
+	now := b.now()
+	transition := c.state
+
+	if c.state == CircuitOpen && now.Sub(c.openedAt) >= b.config.OpenDuration {
+		c.state, c.probes, c.successes = CircuitHalfOpen, 0, 0
+	}
+
+	allowed := true
+
+	switch c.state {
+	case CircuitOpen:
+		allowed = false
+	case CircuitHalfOpen:
+		if c.probes >= b.config.HalfOpenRequests {
+			allowed = false
+		} else {
+			c.probes++
+		}
+	}
+
+	to := c.state
+	b.mu.Unlock()
+
+	b.notify(key, transition, to)
+
+	return allowed
+}
+
+// release gives back a half-open probe slot taken by a call which did not complete.
+func (b *CircuitBreaker) release(key CircuitKey) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if c := b.circuit(key); c.state == CircuitHalfOpen && c.probes > 0 {
+		c.probes--
+	}
+}
+
+// record counts the outcome of a call and updates the circuit state.
+func (b *CircuitBreaker) record(key CircuitKey, failed bool) {
+	b.mu.Lock()
+
+	c := b.circuit(key)
+	now := b.now()
+	from := c.state
+
+	switch c.state {
+	case CircuitClosed:
+		if now.Sub(c.windowStart) >= b.config.Window {
+			c.windowStart, c.requests, c.failures = now, 0, 0
+		}
+
+		c.requests++
+		if failed {
+			c.failures++
+		}
+
+		if c.requests >= b.config.MinRequests && float64(c.failures)/float64(c.requests) >= b.config.FailureRate {
+			c.state, c.openedAt = CircuitOpen, now
+		}
+	case CircuitHalfOpen:
+		if failed {
+			c.state, c.openedAt = CircuitOpen, now
+			break
+		}
+
+		c.successes++
+		if c.successes >= b.config.HalfOpenRequests {
+			c.state, c.windowStart, c.requests, c.failures = CircuitClosed, now, 0, 0
+		}
+	}
+
+	to := c.state
+	b.mu.Unlock()
+
+	b.notify(key, from, to)
+}
+
+func (b *CircuitBreaker) circuit(key CircuitKey) *circuit {
+	c, ok := b.circuits[key]
+	if !ok {
+		c = &circuit{windowStart: b.now()}
+		b.circuits[key] = c
+	}
+
+	return c
+}
+
+func (b *CircuitBreaker) notify(key CircuitKey, from, to CircuitState) {
+	if from != to && b.config.OnStateChange != nil {
+		b.config.OnStateChange(key, from, to)
+	}
+}
@@ -0,0 +1,85 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_WithCircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	var (
+		healthy atomic.Bool
+		calls   atomic.Int32
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(server.Close)
+
+	var transitions []CircuitState
+
+	breaker := NewCircuitBreaker(CircuitBreakerConfig{
+		MinRequests:  2,
+		OpenDuration: time.Minute,
+		OnStateChange: func(key CircuitKey, from, to CircuitState) {
+			if key != (CircuitKey{Operation: OperationEmbeddings, Model: "ada"}) {
+				t.Errorf("unexpected circuit key %+v", key)
+			}
+			transitions = append(transitions, to)
+		},
+	})
+
+	now := time.Now()
+	breaker.now = func() time.Time { return now }
+
+	client := NewClient("test", WithBaseURL(server.URL), WithCircuitBreaker(breaker))
+
+	embed := func() error {
+		_, err := client.Embedding(context.Background(), EmbeddingRequest{Model: "ada"})
+		return err
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := embed(); err == nil {
+			t.Fatal("expected server error")
+		}
+	}
+
+	if err := embed(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected circuit open error, got %v", err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("expected 2 calls to reach the server, got %d", got)
+	}
+
+	now = now.Add(time.Minute)
+	healthy.Store(true)
+
+	if err := embed(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := breaker.State(OperationEmbeddings, "ada"); got != CircuitClosed {
+		t.Fatalf("expected circuit to be closed, got %s", got)
+	}
+
+	want := []CircuitState{CircuitOpen, CircuitHalfOpen, CircuitClosed}
+	if !reflect.DeepEqual(transitions, want) {
+		t.Fatalf("expected transitions %v, got %v", want, transitions)
+	}
+}
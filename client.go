@@ -8,17 +8,23 @@ import (
 const baseURL = "https://api.openai.com/v1"
 
 type Client struct {
-	apiKey       string
-	organization string
-	baseURL      string
-	httpClient   *http.Client
+	credentials   CredentialProvider
+	organization  string
+	baseURL       string
+	httpClient    *http.Client
+	middlewares   []Middleware
+	azure         *AzureConfig
+	balancer      *Balancer
+	fallback      FallbackPolicy
+	cache         *ResponseCache
+	semanticCache *SemanticCache
 }
 
 func NewClient(apiKey string, options ...ClientOption) *Client {
 	client := &Client{
-		apiKey:     apiKey,
-		baseURL:    baseURL,
-		httpClient: http.DefaultClient,
+		credentials: StaticCredentials(apiKey),
+		baseURL:     baseURL,
+		httpClient:  http.DefaultClient,
 	}
 
 	for _, option := range options {
@@ -30,118 +36,130 @@ func NewClient(apiKey string, options ...ClientOption) *Client {
 
 // Models lists the currently available models,
 // and provides basic information about each one such as the owner and availability.
-func (c *Client) Models(ctx context.Context) (ModelsResponse, error) {
-	return makeJSONRequest[ModelsResponse](ctx, c, http.MethodGet, "/models", nil)
+func (c *Client) Models(ctx context.Context, options ...RequestOption) (ModelsResponse, error) {
+	return makeJSONRequest[ModelsResponse](ctx, c, OperationModelsList, http.MethodGet, "/models", nil, options)
 }
 
 // Model retrieves a model instance, providing basic information about the model such as the owner and permissioning.
-func (c *Client) Model(ctx context.Context, id string) (Model, error) {
-	return makeJSONRequest[Model](ctx, c, http.MethodGet, "/models/"+id, nil)
+func (c *Client) Model(ctx context.Context, id string, options ...RequestOption) (Model, error) {
+	return makeJSONRequest[Model](ctx, c, OperationModelsRetrieve, http.MethodGet, "/models/"+id, nil, options)
 }
 
 // DeleteModel delete a fine-tuned model.
 // You must have the Owner role in your organization.
-func (c *Client) DeleteModel(ctx context.Context, id string) (DeleteModelResponse, error) {
-	return makeJSONRequest[DeleteModelResponse](ctx, c, http.MethodDelete, "/models/"+id, nil)
+func (c *Client) DeleteModel(ctx context.Context, id string, options ...RequestOption) (DeleteModelResponse, error) {
+	return makeJSONRequest[DeleteModelResponse](ctx, c, OperationModelsDelete, http.MethodDelete, "/models/"+id, nil, options)
 }
 
 // Completion creates a completion for the provided prompt and parameters.
-func (c *Client) Completion(ctx context.Context, request CompletionRequest) (CompletionResponse, error) {
-	return makeJSONRequest[CompletionResponse](ctx, c, http.MethodPost, "/completions", request)
+func (c *Client) Completion(ctx context.Context, request CompletionRequest, options ...RequestOption) (CompletionResponse, error) {
+	resp, model, err := withFallback(ctx, c, request.Model, options, func(ctx context.Context, model string) (CompletionResponse, error) {
+		request.Model = model
+		return makeJSONRequest[CompletionResponse](ctx, c, OperationCompletions, http.MethodPost, "/completions", request, options)
+	})
+	resp.AnsweredBy = model
+
+	return resp, err
 }
 
 // ChatCompletion creates a completion for the chat message.
-func (c *Client) ChatCompletion(ctx context.Context, request ChatCompletionRequest) (ChatCompletionResponse, error) {
-	return makeJSONRequest[ChatCompletionResponse](ctx, c, http.MethodPost, "/chat/completions", request)
+func (c *Client) ChatCompletion(ctx context.Context, request ChatCompletionRequest, options ...RequestOption) (ChatCompletionResponse, error) {
+	resp, model, err := withFallback(ctx, c, request.Model, options, func(ctx context.Context, model string) (ChatCompletionResponse, error) {
+		request.Model = model
+		return makeJSONRequest[ChatCompletionResponse](ctx, c, OperationChatCompletions, http.MethodPost, "/chat/completions", request, options)
+	})
+	resp.AnsweredBy = model
+
+	return resp, err
 }
 
 // Edit creates a new edit for the provided input, instruction, and parameters.
-func (c *Client) Edit(ctx context.Context, request EditRequest) (EditResponse, error) {
-	return makeJSONRequest[EditResponse](ctx, c, http.MethodPost, "/edits", request)
+func (c *Client) Edit(ctx context.Context, request EditRequest, options ...RequestOption) (EditResponse, error) {
+	return makeJSONRequest[EditResponse](ctx, c, OperationEdits, http.MethodPost, "/edits", request, options)
 }
 
 // Image given a prompt and/or an input image, the model will generate a new image.
-func (c *Client) Image(ctx context.Context, request ImageRequest) (ImageResponse, error) {
-	return makeJSONRequest[ImageResponse](ctx, c, http.MethodPost, "/images/generations", request)
+func (c *Client) Image(ctx context.Context, request ImageRequest, options ...RequestOption) (ImageResponse, error) {
+	return makeJSONRequest[ImageResponse](ctx, c, OperationImagesGenerate, http.MethodPost, "/images/generations", request, options)
 }
 
 // ImageEdit creates an edited or extended image given an original image and a prompt.
-func (c *Client) ImageEdit(ctx context.Context, request ImageEditRequest) (ImageEditResponse, error) {
-	return makeFormDataRequest[ImageEditResponse](ctx, c, "/images/edits", request)
+func (c *Client) ImageEdit(ctx context.Context, request ImageEditRequest, options ...RequestOption) (ImageEditResponse, error) {
+	return makeFormDataRequest[ImageEditResponse](ctx, c, OperationImagesEdit, "/images/edits", request, options)
 }
 
 // ImageVariation creates a variation of a given image.
-func (c *Client) ImageVariation(ctx context.Context, request ImageVariationRequest) (ImageVariationResponse, error) {
-	return makeFormDataRequest[ImageVariationResponse](ctx, c, "/images/variations", request)
+func (c *Client) ImageVariation(ctx context.Context, request ImageVariationRequest, options ...RequestOption) (ImageVariationResponse, error) {
+	return makeFormDataRequest[ImageVariationResponse](ctx, c, OperationImagesVariation, "/images/variations", request, options)
 }
 
 // Embedding creates an embedding vector representing the input text.
-func (c *Client) Embedding(ctx context.Context, request EmbeddingRequest) (EmbeddingResponse, error) {
-	return makeJSONRequest[EmbeddingResponse](ctx, c, http.MethodPost, "/embeddings", request)
+func (c *Client) Embedding(ctx context.Context, request EmbeddingRequest, options ...RequestOption) (EmbeddingResponse, error) {
+	return makeJSONRequest[EmbeddingResponse](ctx, c, OperationEmbeddings, http.MethodPost, "/embeddings", request, options)
 }
 
 // Transcription transcribes audio into the input language.
-func (c *Client) Transcription(ctx context.Context, request TranscriptionRequest) (TranscriptionResponse, error) {
-	return makeFormDataRequest[TranscriptionResponse](ctx, c, "/audio/transcriptions", request)
+func (c *Client) Transcription(ctx context.Context, request TranscriptionRequest, options ...RequestOption) (TranscriptionResponse, error) {
+	return makeFormDataRequest[TranscriptionResponse](ctx, c, OperationAudioTranscriptions, "/audio/transcriptions", request, options)
 }
 
 // Translation translates audio into English.
-func (c *Client) Translation(ctx context.Context, request TranslationRequest) (TranslationResponse, error) {
-	return makeFormDataRequest[TranslationResponse](ctx, c, "/audio/translations", request)
+func (c *Client) Translation(ctx context.Context, request TranslationRequest, options ...RequestOption) (TranslationResponse, error) {
+	return makeFormDataRequest[TranslationResponse](ctx, c, OperationAudioTranslations, "/audio/translations", request, options)
 }
 
 // Files returns a list of files that belong to the user's organization.
-func (c *Client) Files(ctx context.Context) (FilesResponse, error) {
-	return makeJSONRequest[FilesResponse](ctx, c, http.MethodGet, "/files", nil)
+func (c *Client) Files(ctx context.Context, options ...RequestOption) (FilesResponse, error) {
+	return makeJSONRequest[FilesResponse](ctx, c, OperationFilesList, http.MethodGet, "/files", nil, options)
 }
 
 // UploadFile upload a file that contains document(s) to be used across various endpoints/features.
 // Currently, the size of all the files uploaded by one organization can be up to 1 GB.
-func (c *Client) UploadFile(ctx context.Context, request UploadFileRequest) (File, error) {
-	return makeFormDataRequest[File](ctx, c, "/files", request)
+func (c *Client) UploadFile(ctx context.Context, request UploadFileRequest, options ...RequestOption) (File, error) {
+	return makeFormDataRequest[File](ctx, c, OperationFilesUpload, "/files", request, options)
 }
 
 // DeleteFile deletes a file.
-func (c *Client) DeleteFile(ctx context.Context, id string) (DeleteFileResponse, error) {
-	return makeJSONRequest[DeleteFileResponse](ctx, c, http.MethodDelete, "/files/"+id, nil)
+func (c *Client) DeleteFile(ctx context.Context, id string, options ...RequestOption) (DeleteFileResponse, error) {
+	return makeJSONRequest[DeleteFileResponse](ctx, c, OperationFilesDelete, http.MethodDelete, "/files/"+id, nil, options)
 }
 
 // File returns information about a specific file.
-func (c *Client) File(ctx context.Context, id string) (File, error) {
-	return makeJSONRequest[File](ctx, c, http.MethodGet, "/files/"+id, nil)
+func (c *Client) File(ctx context.Context, id string, options ...RequestOption) (File, error) {
+	return makeJSONRequest[File](ctx, c, OperationFilesRetrieve, http.MethodGet, "/files/"+id, nil, options)
 }
 
 // FileContent returns the contents of the specified file.
-func (c *Client) FileContent(ctx context.Context, id string) (string, error) {
-	return makeJSONRequest[string](ctx, c, http.MethodGet, "/files/"+id+"/content", nil)
+func (c *Client) FileContent(ctx context.Context, id string, options ...RequestOption) (string, error) {
+	return makeJSONRequest[string](ctx, c, OperationFilesContent, http.MethodGet, "/files/"+id+"/content", nil, options)
 }
 
 // CreateFineTune creates a job that fine-tunes a specified model from a given dataset.
-func (c *Client) CreateFineTune(ctx context.Context, request FineTuneRequest) (FineTune, error) {
-	return makeJSONRequest[FineTune](ctx, c, http.MethodPost, "/fine-tunes", request)
+func (c *Client) CreateFineTune(ctx context.Context, request FineTuneRequest, options ...RequestOption) (FineTune, error) {
+	return makeJSONRequest[FineTune](ctx, c, OperationFineTunesCreate, http.MethodPost, "/fine-tunes", request, options)
 }
 
 // FineTunes list your organization's fine-tuning jobs.
-func (c *Client) FineTunes(ctx context.Context) (FineTunesResponse, error) {
-	return makeJSONRequest[FineTunesResponse](ctx, c, http.MethodGet, "/fine-tunes", nil)
+func (c *Client) FineTunes(ctx context.Context, options ...RequestOption) (FineTunesResponse, error) {
+	return makeJSONRequest[FineTunesResponse](ctx, c, OperationFineTunesList, http.MethodGet, "/fine-tunes", nil, options)
 }
 
 // FineTune gets info about the fine-tune job.
-func (c *Client) FineTune(ctx context.Context, id string) (FineTune, error) {
-	return makeJSONRequest[FineTune](ctx, c, http.MethodGet, "/fine-tunes"+id, nil)
+func (c *Client) FineTune(ctx context.Context, id string, options ...RequestOption) (FineTune, error) {
+	return makeJSONRequest[FineTune](ctx, c, OperationFineTunesRetrieve, http.MethodGet, "/fine-tunes"+id, nil, options)
 }
 
 // CancelFineTune immediately cancel a fine-tune job.
-func (c *Client) CancelFineTune(ctx context.Context, id string) (FineTune, error) {
-	return makeJSONRequest[FineTune](ctx, c, http.MethodPost, "/fine-tunes"+id+"/cancel", nil)
+func (c *Client) CancelFineTune(ctx context.Context, id string, options ...RequestOption) (FineTune, error) {
+	return makeJSONRequest[FineTune](ctx, c, OperationFineTunesCancel, http.MethodPost, "/fine-tunes"+id+"/cancel", nil, options)
 }
 
 // FineTuneEvents get fine-grained status updates for a fine-tune job.
-func (c *Client) FineTuneEvents(ctx context.Context, id string) (FineTuneEventsResponse, error) {
-	return makeJSONRequest[FineTuneEventsResponse](ctx, c, http.MethodGet, "/fine-tunes"+id+"/events", nil)
+func (c *Client) FineTuneEvents(ctx context.Context, id string, options ...RequestOption) (FineTuneEventsResponse, error) {
+	return makeJSONRequest[FineTuneEventsResponse](ctx, c, OperationFineTunesEvents, http.MethodGet, "/fine-tunes"+id+"/events", nil, options)
 }
 
 // Moderation classifies if text violates OpenAI's Content Policy
-func (c *Client) Moderation(ctx context.Context, request ModerationRequest) (ModerationResponse, error) {
-	return makeJSONRequest[ModerationResponse](ctx, c, http.MethodPost, "/moderations", request)
+func (c *Client) Moderation(ctx context.Context, request ModerationRequest, options ...RequestOption) (ModerationResponse, error) {
+	return makeJSONRequest[ModerationResponse](ctx, c, OperationModerations, http.MethodPost, "/moderations", request, options)
 }
@@ -21,3 +21,12 @@ func WithBaseURL(baseURL string) ClientOption {
 		c.baseURL = baseURL
 	}
 }
+
+// WithMiddleware adds middlewares wrapping every API call.
+// The first middleware is the outermost one, so WithMeter and WithBudget added after WithHedging
+// or WithDeduplication count every API call sent, not only the responses returned to the callers.
+func WithMiddleware(middlewares ...Middleware) ClientOption {
+	return func(c *Client) {
+		c.middlewares = append(c.middlewares, middlewares...)
+	}
+}
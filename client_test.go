@@ -71,8 +71,8 @@ func TestClient_Completion(t *testing.T) {
 		Model:       "ada",
 		Prompt:      []string{"This is a test"},
 		MaxTokens:   5,
-		Temperature: 0.9,
-		N:           3,
+		Temperature: Float(0.9),
+		N:           Int(3),
 	})
 	if err != nil {
 		t.Fatal(err)
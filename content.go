@@ -0,0 +1,211 @@
+package openai
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/png"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+type ChatContentPartType string
+
+const (
+	ChatContentPartTypeText       ChatContentPartType = "text"
+	ChatContentPartTypeImageURL   ChatContentPartType = "image_url"
+	ChatContentPartTypeInputAudio ChatContentPartType = "input_audio"
+	ChatContentPartTypeFile       ChatContentPartType = "file"
+)
+
+type ImageDetail string
+
+const (
+	ImageDetailAuto ImageDetail = "auto"
+	ImageDetailLow  ImageDetail = "low"
+	ImageDetailHigh ImageDetail = "high"
+)
+
+type AudioFormat string
+
+const (
+	AudioFormatWAV AudioFormat = "wav"
+	AudioFormatMP3 AudioFormat = "mp3"
+)
+
+// ChatContentPart is a single part of a multimodal chat message.
+// Only the field matching Type is expected to be set.
+type ChatContentPart struct {
+	Type       ChatContentPartType    `json:"type"`
+	Text       string                 `json:"text,omitempty"`
+	ImageURL   *ChatContentImageURL   `json:"image_url,omitempty"`
+	InputAudio *ChatContentInputAudio `json:"input_audio,omitempty"`
+	File       *ChatContentFile       `json:"file,omitempty"`
+}
+
+type ChatContentImageURL struct {
+	URL    string      `json:"url"`
+	Detail ImageDetail `json:"detail,omitempty"`
+}
+
+type ChatContentInputAudio struct {
+	Data   string      `json:"data"`
+	Format AudioFormat `json:"format"`
+}
+
+type ChatContentFile struct {
+	FileID   string `json:"file_id,omitempty"`
+	FileData string `json:"file_data,omitempty"`
+	Filename string `json:"filename,omitempty"`
+}
+
+// TextPart creates a text content part.
+func TextPart(text string) ChatContentPart {
+	return ChatContentPart{Type: ChatContentPartTypeText, Text: text}
+}
+
+// ImageURLPart creates an image content part from an image URL or a data URL.
+func ImageURLPart(url string, detail ImageDetail) ChatContentPart {
+	return ChatContentPart{
+		Type:     ChatContentPartTypeImageURL,
+		ImageURL: &ChatContentImageURL{URL: url, Detail: detail},
+	}
+}
+
+// ImagePart creates an image content part from the given image encoded as a PNG data URL.
+func ImagePart(img image.Image, detail ImageDetail) (ChatContentPart, error) {
+	url, err := ImageDataURL(img)
+	if err != nil {
+		return ChatContentPart{}, err
+	}
+
+	return ImageURLPart(url, detail), nil
+}
+
+// ImageFilePart creates an image content part from a local image file encoded as a data URL.
+func ImageFilePart(path string, detail ImageDetail) (ChatContentPart, error) {
+	url, err := FileDataURL(path)
+	if err != nil {
+		return ChatContentPart{}, err
+	}
+
+	return ImageURLPart(url, detail), nil
+}
+
+// InputAudioPart creates an audio content part from raw audio data.
+func InputAudioPart(data []byte, format AudioFormat) ChatContentPart {
+	return ChatContentPart{
+		Type: ChatContentPartTypeInputAudio,
+		InputAudio: &ChatContentInputAudio{
+			Data:   base64.StdEncoding.EncodeToString(data),
+			Format: format,
+		},
+	}
+}
+
+// FilePart creates a file content part referencing a previously uploaded file.
+func FilePart(fileID string) ChatContentPart {
+	return ChatContentPart{
+		Type: ChatContentPartTypeFile,
+		File: &ChatContentFile{FileID: fileID},
+	}
+}
+
+// FileDataPart creates a file content part from a local file encoded as a data URL.
+func FileDataPart(path string) (ChatContentPart, error) {
+	url, err := FileDataURL(path)
+	if err != nil {
+		return ChatContentPart{}, err
+	}
+
+	return ChatContentPart{
+		Type: ChatContentPartTypeFile,
+		File: &ChatContentFile{FileData: url, Filename: filepath.Base(path)},
+	}, nil
+}
+
+// ImageDataURL encodes the given image as a PNG data URL.
+func ImageDataURL(img image.Image) (string, error) {
+	buf := new(bytes.Buffer)
+
+	if err := png.Encode(buf, img); err != nil {
+		return "", err
+	}
+
+	return dataURL("image/png", buf.Bytes()), nil
+}
+
+// FileDataURL reads a local file and encodes it as a data URL.
+// The media type is derived from the file extension, falling back to content sniffing.
+func FileDataURL(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	mediaType := mime.TypeByExtension(filepath.Ext(path))
+	if mediaType == "" {
+		mediaType = http.DetectContentType(data)
+	}
+
+	return dataURL(mediaType, data), nil
+}
+
+func dataURL(mediaType string, data []byte) string {
+	return "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(data)
+}
+
+type chatCompletionRequestMessageJSON struct {
+	Role    ChatRole        `json:"role"`
+	Content json.RawMessage `json:"content"`
+	Name    string          `json:"name,omitempty"`
+}
+
+func (m ChatCompletionRequestMessage) MarshalJSON() ([]byte, error) {
+	var (
+		content []byte
+		err     error
+	)
+
+	if len(m.MultiContent) > 0 {
+		content, err = json.Marshal(m.MultiContent)
+	} else {
+		content, err = json.Marshal(m.Content)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(chatCompletionRequestMessageJSON{
+		Role:    m.Role,
+		Content: content,
+		Name:    m.Name,
+	})
+}
+
+func (m *ChatCompletionRequestMessage) UnmarshalJSON(data []byte) error {
+	var message chatCompletionRequestMessageJSON
+
+	if err := json.Unmarshal(data, &message); err != nil {
+		return err
+	}
+
+	*m = ChatCompletionRequestMessage{Role: message.Role, Name: message.Name}
+
+	content := bytes.TrimSpace(message.Content)
+
+	switch {
+	case len(content) == 0 || bytes.Equal(content, []byte("null")):
+		return nil
+	case content[0] == '"':
+		return json.Unmarshal(content, &m.Content)
+	case content[0] == '[':
+		return json.Unmarshal(content, &m.MultiContent)
+	default:
+		return errors.New("openai: message content must be a string or an array of content parts")
+	}
+}
@@ -0,0 +1,82 @@
+package openai
+
+import (
+	"encoding/json"
+	"image"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestChatCompletionRequestMessage_JSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		message ChatCompletionRequestMessage
+		want    string
+	}{
+		{
+			name:    "string content",
+			message: ChatCompletionRequestMessage{Role: ChatRoleUser, Content: "Hello"},
+			want:    `{"role":"user","content":"Hello"}`,
+		},
+		{
+			name: "multi content",
+			message: ChatCompletionRequestMessage{
+				Role: ChatRoleUser,
+				MultiContent: []ChatContentPart{
+					TextPart("What is in this image?"),
+					ImageURLPart("https://example.com/cat.png", ImageDetailLow),
+				},
+				Name: "john",
+			},
+			want: `{"role":"user","content":[{"type":"text","text":"What is in this image?"},` +
+				`{"type":"image_url","image_url":{"url":"https://example.com/cat.png","detail":"low"}}],"name":"john"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			data, err := json.Marshal(tc.message)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := string(data); got != tc.want {
+				t.Fatalf("expected %s, got %s", tc.want, got)
+			}
+
+			var message ChatCompletionRequestMessage
+
+			if err = json.Unmarshal(data, &message); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(message, tc.message) {
+				t.Fatalf("expected %+v, got %+v", tc.message, message)
+			}
+		})
+	}
+}
+
+func TestImagePart(t *testing.T) {
+	t.Parallel()
+
+	part, err := ImagePart(image.NewNRGBA(image.Rect(0, 0, 8, 8)), ImageDetailHigh)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if part.Type != ChatContentPartTypeImageURL {
+		t.Fatalf("expected type to be %s, got %s", ChatContentPartTypeImageURL, part.Type)
+	}
+
+	if !strings.HasPrefix(part.ImageURL.URL, "data:image/png;base64,") {
+		t.Fatalf("expected png data url, got %s", part.ImageURL.URL)
+	}
+}
@@ -0,0 +1,174 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// credentialExpirySkew is how long before its expiry a credential is refreshed.
+const credentialExpirySkew = 30 * time.Second
+
+// Credential is an API key with an optional expiry.
+type Credential struct {
+	APIKey string
+	// ExpiresAt is the time after which the key must be fetched again. Zero means the key does not expire.
+	ExpiresAt time.Time
+}
+
+// CredentialProvider provides the API key used to authenticate requests.
+// It is called for every request; wrap providers doing expensive lookups with CachedCredentials.
+type CredentialProvider interface {
+	Credential(ctx context.Context) (Credential, error)
+}
+
+// CredentialInvalidator is implemented by providers caching credentials.
+// When a request fails with 401 Unauthorized, the Client invalidates the rejected key
+// and retries the request once if the provider then returns a different key.
+type CredentialInvalidator interface {
+	Invalidate(apiKey string)
+}
+
+// CredentialProviderFunc adapts a function to CredentialProvider, e.g. to fetch keys from a secrets manager.
+type CredentialProviderFunc func(ctx context.Context) (Credential, error)
+
+func (f CredentialProviderFunc) Credential(ctx context.Context) (Credential, error) {
+	return f(ctx)
+}
+
+// StaticCredentials provides the given API key.
+func StaticCredentials(apiKey string) CredentialProvider {
+	return CredentialProviderFunc(func(context.Context) (Credential, error) {
+		return Credential{APIKey: apiKey}, nil
+	})
+}
+
+// EnvCredentials provides the API key stored in the environment variable with the given name.
+// The variable is read again whenever the cached key is refreshed.
+func EnvCredentials(name string) CredentialProvider {
+	return CredentialProviderFunc(func(context.Context) (Credential, error) {
+		apiKey := os.Getenv(name)
+		if apiKey == "" {
+			return Credential{}, fmt.Errorf("openai: environment variable %s is empty", name)
+		}
+
+		return Credential{APIKey: apiKey}, nil
+	})
+}
+
+// FileCredentials provides the API key stored in the file at path, e.g. a mounted secret.
+// The file is read again whenever it changes.
+func FileCredentials(path string) CredentialProvider {
+	return &fileCredentials{path: path}
+}
+
+type fileCredentials struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	apiKey  string
+}
+
+func (f *fileCredentials) Credential(context.Context) (Credential, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return Credential{}, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.apiKey == "" || !info.ModTime().Equal(f.modTime) || info.Size() != f.size {
+		data, err := os.ReadFile(f.path)
+		if err != nil {
+			return Credential{}, err
+		}
+
+		apiKey := string(bytes.TrimSpace(data))
+		if apiKey == "" {
+			return Credential{}, fmt.Errorf("openai: credentials file %s is empty", f.path)
+		}
+
+		f.apiKey, f.modTime, f.size = apiKey, info.ModTime(), info.Size()
+	}
+
+	return Credential{APIKey: f.apiKey}, nil
+}
+
+// CachedCredentials caches the credential of the provider until it expires or is invalidated
+// after a request failed with 401 Unauthorized.
+func CachedCredentials(provider CredentialProvider) CredentialProvider {
+	return &cachedCredentials{provider: provider}
+}
+
+type cachedCredentials struct {
+	provider CredentialProvider
+
+	mu      sync.Mutex
+	current *Credential
+}
+
+func (c *cachedCredentials) Credential(ctx context.Context) (Credential, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.current != nil && (c.current.ExpiresAt.IsZero() || time.Until(c.current.ExpiresAt) > credentialExpirySkew) {
+		return *c.current, nil
+	}
+
+	credential, err := c.provider.Credential(ctx)
+	if err != nil {
+		return Credential{}, err
+	}
+
+	c.current = &credential
+
+	return credential, nil
+}
+
+// Invalidate drops the cached credential if it is still the given key,
+// so a key refreshed concurrently by another request is kept.
+func (c *cachedCredentials) Invalidate(apiKey string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.current != nil && c.current.APIKey == apiKey {
+		c.current = nil
+	}
+}
+
+// WithCredentialProvider authenticates requests with the API keys of the provider instead of the static key
+// passed to NewClient.
+func WithCredentialProvider(provider CredentialProvider) ClientOption {
+	return func(c *Client) {
+		c.credentials = provider
+	}
+}
+
+// credential returns the API key for the next request.
+// It may be empty, e.g. for proxies adding the key themselves or balancer backends with their own keys.
+func (c *Client) credential(ctx context.Context) (string, error) {
+	credential, err := c.credentials.Credential(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return credential.APIKey, nil
+}
+
+// refreshCredential invalidates the rejected key and reports whether the provider returns a different one.
+func (c *Client) refreshCredential(ctx context.Context, rejected string) bool {
+	invalidator, ok := c.credentials.(CredentialInvalidator)
+	if ok {
+		invalidator.Invalidate(rejected)
+	}
+
+	apiKey, err := c.credential(ctx)
+
+	return err == nil && apiKey != rejected
+}
@@ -0,0 +1,117 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_CredentialRefresh(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer key-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":{"message":"Incorrect API key provided","type":"invalid_request_error"}}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"object":"list"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	var fetches atomic.Int32
+
+	provider := CachedCredentials(CredentialProviderFunc(func(context.Context) (Credential, error) {
+		if fetches.Add(1) == 1 {
+			return Credential{APIKey: "key-1", ExpiresAt: time.Now().Add(time.Hour)}, nil
+		}
+		return Credential{APIKey: "key-2", ExpiresAt: time.Now().Add(time.Hour)}, nil
+	}))
+
+	client := NewClient("", WithBaseURL(server.URL), WithCredentialProvider(provider))
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Models(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := fetches.Load(); got != 2 {
+		t.Fatalf("expected 2 credential fetches, got %d", got)
+	}
+}
+
+func TestFileCredentials(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "api-key")
+
+	if err := os.WriteFile(path, []byte("key-1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	provider := FileCredentials(path)
+
+	credential, err := provider.Credential(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if credential.APIKey != "key-1" {
+		t.Fatalf("expected key-1, got %q", credential.APIKey)
+	}
+
+	if err = os.WriteFile(path, []byte("key-22\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if credential, err = provider.Credential(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if credential.APIKey != "key-22" {
+		t.Fatalf("expected key-22, got %q", credential.APIKey)
+	}
+}
+
+func TestClient_EmptyAPIKey(t *testing.T) {
+	t.Parallel()
+
+	newServer := func(authorization string) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if got := r.Header.Get("Authorization"); got != authorization {
+				t.Errorf("expected Authorization header to be %q, got %q", authorization, got)
+			}
+
+			_, _ = w.Write([]byte(`{"object":"list"}`))
+		}))
+		t.Cleanup(server.Close)
+
+		return server
+	}
+
+	t.Run("proxy", func(t *testing.T) {
+		t.Parallel()
+
+		client := NewClient("", WithBaseURL(newServer("").URL))
+
+		if _, err := client.Models(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("balancer", func(t *testing.T) {
+		t.Parallel()
+
+		balancer := NewBalancer([]Backend{{BaseURL: newServer("Bearer backend-key").URL, APIKey: "backend-key"}})
+		client := NewClient("", WithBalancer(balancer))
+
+		if _, err := client.Models(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
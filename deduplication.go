@@ -0,0 +1,171 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// sharedResponseHeader marks the copies of a deduplicated response given to the callers other than the first one,
+// so middlewares outside the Deduplicator count the API call once.
+const sharedResponseHeader = "X-Openai-Go-Shared"
+
+// Deduplicator collapses identical concurrent requests into a single API call whose response, or error,
+// is shared by all callers. Requests are identical if they have the same method, URL, canonical JSON body
+// and credentials. Completions and chat completions are only deduplicated with a temperature of 0.
+//
+// The shared call is cancelled only once every caller waiting for it gave up,
+// so one caller cancelling its context does not fail the others.
+type Deduplicator struct {
+	operations map[string]bool
+
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+type flight struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int
+	// claimed is set once a caller got the response, the others get it marked as shared.
+	claimed atomic.Bool
+
+	resp *http.Response
+	body []byte
+	err  error
+}
+
+// NewDeduplicator creates a Deduplicator of the given operations.
+// Defaults to completions, chat completions, embeddings and moderations.
+func NewDeduplicator(operations ...string) *Deduplicator {
+	if len(operations) == 0 {
+		operations = []string{OperationCompletions, OperationChatCompletions, OperationEmbeddings, OperationModerations}
+	}
+
+	deduplicator := &Deduplicator{
+		operations: make(map[string]bool, len(operations)),
+		flights:    make(map[string]*flight),
+	}
+
+	for _, operation := range operations {
+		deduplicator.operations[operation] = true
+	}
+
+	return deduplicator
+}
+
+// WithDeduplication collapses identical concurrent requests of the Client.
+func WithDeduplication(deduplicator *Deduplicator) ClientOption {
+	return WithMiddleware(DeduplicationMiddleware(deduplicator))
+}
+
+// DeduplicationMiddleware collapses identical concurrent requests.
+func DeduplicationMiddleware(deduplicator *Deduplicator) Middleware {
+	return func(next Handler) Handler {
+		return func(op Operation, req *http.Request) (*http.Response, error) {
+			if !deduplicator.operations[op.Name] || !deterministic(op) {
+				return next(op, req)
+			}
+
+			body := requestBody(req)
+			if body == nil {
+				return next(op, req)
+			}
+
+			return deduplicator.do(op, req, cacheKey(req, body), next)
+		}
+	}
+}
+
+func (d *Deduplicator) do(op Operation, req *http.Request, key string, next Handler) (*http.Response, error) {
+	d.mu.Lock()
+
+	f, ok := d.flights[key]
+	if !ok {
+		sharedReq, err := rewindRequest(req)
+		if err != nil {
+			d.mu.Unlock()
+			return nil, err
+		}
+
+		// The shared call keeps the values of the first caller context, e.g. tracing spans, but not its cancellation.
+		ctx, cancel := context.WithCancel(context.WithoutCancel(req.Context()))
+
+		f = &flight{done: make(chan struct{}), cancel: cancel}
+		d.flights[key] = f
+
+		go d.run(f, key, op, sharedReq.WithContext(ctx), next)
+	}
+
+	f.waiters++
+	d.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.response(req, !f.claimed.CompareAndSwap(false, true))
+	case <-req.Context().Done():
+		d.leave(f, key)
+		return nil, req.Context().Err()
+	}
+}
+
+func (d *Deduplicator) run(f *flight, key string, op Operation, req *http.Request, next Handler) {
+	defer f.cancel()
+
+	resp, err := next(op, req)
+	if err == nil {
+		// The body is read before the context of the call is cancelled, so it can be shared.
+		f.body, err = readResponseBody(resp)
+	}
+
+	f.resp, f.err = resp, err
+
+	d.mu.Lock()
+	if d.flights[key] == f {
+		delete(d.flights, key)
+	}
+	d.mu.Unlock()
+
+	close(f.done)
+}
+
+// leave removes a waiter from the flight, cancelling it if no one waits for it anymore.
+func (d *Deduplicator) leave(f *flight, key string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if f.waiters--; f.waiters == 0 {
+		f.cancel()
+
+		// Later identical requests start a new call instead of joining the cancelled one.
+		if d.flights[key] == f {
+			delete(d.flights, key)
+		}
+	}
+}
+
+// response returns a copy of the shared response for the request.
+func (f *flight) response(req *http.Request, shared bool) (*http.Response, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	resp := *f.resp
+	resp.Header = f.resp.Header.Clone()
+	resp.Body = io.NopCloser(bytes.NewReader(f.body))
+	resp.Request = req
+
+	if shared {
+		resp.Header.Set(sharedResponseHeader, "true")
+	}
+
+	return &resp, nil
+}
+
+// sharedResponse reports whether the response is a copy of a response already given to another caller.
+func sharedResponse(resp *http.Response) bool {
+	return resp.Header.Get(sharedResponseHeader) != ""
+}
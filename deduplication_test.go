@@ -0,0 +1,128 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_WithDeduplication(t *testing.T) {
+	t.Parallel()
+
+	var (
+		calls    atomic.Int32
+		received = make(chan struct{}, 1)
+		release  = make(chan struct{})
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		received <- struct{}{}
+		<-release
+		_, _ = w.Write([]byte(`{"data":[{"index":0,"embedding":[0.5]}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	// arrived counts the callers about to join the shared call, which the server holds until released.
+	var arrived sync.WaitGroup
+	arrived.Add(10)
+
+	// The meter runs outside the Deduplicator, so it must skip the responses shared with other callers.
+	meter := NewMeter(PriceTable{})
+
+	client := NewClient("test", WithBaseURL(server.URL),
+		WithMiddleware(MutateRequestMiddleware(func(Operation, *http.Request) { arrived.Done() })),
+		WithMeter(meter),
+		WithDeduplication(NewDeduplicator()),
+	)
+
+	var wg sync.WaitGroup
+
+	call := func(ctx context.Context) {
+		defer wg.Done()
+
+		resp, err := client.Embedding(ctx, EmbeddingRequest{Model: "ada", Input: []string{"test"}})
+		if ctx.Err() != nil {
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("expected the cancelled caller to fail with context.Canceled, got %v", err)
+			}
+			return
+		}
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if len(resp.Data) != 1 || resp.Data[0].Embedding[0] != 0.5 {
+			t.Errorf("expected the shared embedding, got %+v", resp.Data)
+		}
+	}
+
+	wg.Add(9)
+	for i := 0; i < 9; i++ {
+		go call(context.Background())
+	}
+
+	// Once the server got the shared call, a caller giving up must not fail the others.
+	<-received
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelledDone := make(chan struct{})
+
+	wg.Add(1)
+	go func() {
+		defer close(cancelledDone)
+		call(ctx)
+	}()
+
+	arrived.Wait()
+	cancel()
+	<-cancelledDone
+
+	close(release)
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("expected 1 call, got %d", got)
+	}
+
+	if got := meter.Snapshot().Total().Requests; got != 1 {
+		t.Fatalf("expected the shared call to be metered once, got %d requests", got)
+	}
+}
+
+func TestClient_WithDeduplication_Cancel(t *testing.T) {
+	t.Parallel()
+
+	cancelled := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The server notices the client went away only once the request body is read.
+		_, _ = io.Copy(io.Discard, r.Body)
+
+		<-r.Context().Done()
+		close(cancelled)
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient("test", WithBaseURL(server.URL), WithDeduplication(NewDeduplicator()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := client.Moderation(ctx, ModerationRequest{Input: []string{"test"}}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the shared call to be cancelled once its only caller left")
+	}
+}
@@ -0,0 +1,328 @@
+package openai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/KirillMironov/openai/internal/tokens"
+)
+
+const (
+	defaultEmbedAllBatchSize   = 512
+	defaultEmbedAllConcurrency = 4
+	defaultEmbedAllMaxRetries  = 3
+)
+
+// EmbedAllOptions configures Client.EmbedAll. Zero values are replaced by the defaults.
+type EmbedAllOptions struct {
+	// BatchSize is the maximum number of inputs per Embedding call. Defaults to 512, capped at 2048.
+	BatchSize int
+	// MaxBatchTokens is the maximum estimated number of input tokens per Embedding call. Defaults to 100000.
+	MaxBatchTokens int
+	// Concurrency is the number of Embedding calls in flight. Defaults to 4.
+	Concurrency int
+	// MaxRetries is the number of retries of a failed batch, see WithMaxRetries. Defaults to 3, Int(0) disables retries.
+	MaxRetries *int
+	// EncodingFormat, Dimensions and User are sent with every Embedding call.
+	EncodingFormat EmbeddingEncodingFormat
+	Dimensions     int
+	User           string
+	// Progress, if set, is called after every completed batch. Calls are not concurrent.
+	Progress func(EmbedAllProgress)
+	// CheckpointPath, if set, is a file the completed batches are appended to.
+	// A later call with the same model, dimensions and inputs resumes from it instead of embedding them again.
+	CheckpointPath string
+	// RequestOptions are applied to every Embedding call.
+	RequestOptions []RequestOption
+}
+
+// EmbedAllProgress reports the progress of Client.EmbedAll.
+type EmbedAllProgress struct {
+	// Done is the number of embedded inputs, including the ones restored from the checkpoint.
+	Done  int
+	Total int
+	Usage Usage
+}
+
+// embedCheckpointHeader is the first line of a checkpoint file, identifying the embedded corpus.
+type embedCheckpointHeader struct {
+	Model      string `json:"model"`
+	Dimensions int    `json:"dimensions,omitempty"`
+	Inputs     int    `json:"inputs"`
+	Digest     string `json:"digest"`
+}
+
+// embedCheckpointBatch is a line of a checkpoint file holding the embeddings of a completed batch.
+type embedCheckpointBatch struct {
+	Indices    []int       `json:"indices"`
+	Embeddings [][]float32 `json:"embeddings"`
+}
+
+// EmbedAll creates the embeddings of any number of inputs.
+// Inputs are split into batches by count and estimated tokens, which are embedded concurrently
+// and retried on failure. The returned embeddings are aligned with the inputs.
+// If a batch keeps failing, the other ones are cancelled and its error is returned.
+func (c *Client) EmbedAll(ctx context.Context, model string, inputs []string, options EmbedAllOptions) ([][]float32, error) {
+	if options.BatchSize <= 0 || options.BatchSize > maxEmbeddingBatchSize {
+		options.BatchSize = defaultEmbedAllBatchSize
+	}
+	if options.MaxBatchTokens <= 0 {
+		options.MaxBatchTokens = defaultEmbeddingBatchTokens
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = defaultEmbedAllConcurrency
+	}
+	maxRetries := defaultEmbedAllMaxRetries
+	if options.MaxRetries != nil {
+		maxRetries = max(*options.MaxRetries, 0)
+	}
+
+	embeddings := make([][]float32, len(inputs))
+
+	var checkpoint *os.File
+
+	if options.CheckpointPath != "" {
+		var err error
+		if checkpoint, err = openEmbedCheckpoint(options.CheckpointPath, model, options.Dimensions, inputs, embeddings); err != nil {
+			return nil, err
+		}
+		defer checkpoint.Close()
+	}
+
+	var pending []int
+	for i := range inputs {
+		if embeddings[i] == nil {
+			pending = append(pending, i)
+		}
+	}
+
+	batches := embedBatches(inputs, pending, options.BatchSize, options.MaxBatchTokens)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	requestOptions := append([]RequestOption{WithMaxRetries(maxRetries)}, options.RequestOptions...)
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		progress = EmbedAllProgress{Done: len(inputs) - len(pending), Total: len(inputs)}
+		queue    = make(chan []int)
+	)
+
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+
+	for i := 0; i < options.Concurrency; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for batch := range queue {
+				request := EmbeddingRequest{
+					Model:          model,
+					Input:          make([]string, len(batch)),
+					EncodingFormat: options.EncodingFormat,
+					Dimensions:     options.Dimensions,
+					User:           options.User,
+				}
+				for j, index := range batch {
+					request.Input[j] = inputs[index]
+				}
+
+				resp, err := c.Embedding(ctx, request, requestOptions...)
+				if err == nil && len(resp.Data) != len(batch) {
+					err = fmt.Errorf("openai: expected %d embeddings, got %d", len(batch), len(resp.Data))
+				}
+				if err != nil {
+					fail(err)
+					continue
+				}
+
+				completed := embedCheckpointBatch{Indices: batch, Embeddings: make([][]float32, len(batch))}
+				for _, data := range resp.Data {
+					if data.Index >= 0 && data.Index < len(batch) {
+						completed.Embeddings[data.Index] = data.Embedding
+					}
+				}
+
+				mu.Lock()
+
+				for j, index := range batch {
+					embeddings[index] = completed.Embeddings[j]
+				}
+
+				if checkpoint != nil {
+					if err = appendJSONLine(checkpoint, completed); err != nil {
+						mu.Unlock()
+						fail(err)
+						continue
+					}
+				}
+
+				progress.Done += len(batch)
+				progress.Usage.PromptTokens += resp.Usage.PromptTokens
+				progress.Usage.TotalTokens += resp.Usage.TotalTokens
+
+				if options.Progress != nil {
+					options.Progress(progress)
+				}
+
+				mu.Unlock()
+			}
+		}()
+	}
+
+send:
+	for _, batch := range batches {
+		select {
+		case queue <- batch:
+		case <-ctx.Done():
+			break send
+		}
+	}
+
+	close(queue)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return embeddings, nil
+}
+
+// embedBatches splits the pending input indices into batches of at most size inputs and maxTokens estimated tokens.
+// An input exceeding maxTokens on its own gets a batch of its own.
+func embedBatches(inputs []string, pending []int, size, maxTokens int) [][]int {
+	var (
+		batches [][]int
+		batch   []int
+		total   int
+	)
+
+	for _, index := range pending {
+		inputTokens := tokens.Estimate(inputs[index])
+
+		if len(batch) > 0 && (len(batch) == size || total+inputTokens > maxTokens) {
+			batches = append(batches, batch)
+			batch, total = nil, 0
+		}
+
+		batch = append(batch, index)
+		total += inputTokens
+	}
+
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+
+	return batches
+}
+
+// openEmbedCheckpoint opens the checkpoint file at path for appending, creating it if needed,
+// and restores the embeddings of the batches it holds.
+// A truncated last line, left by an interrupted write, is removed.
+func openEmbedCheckpoint(path, model string, dimensions int, inputs []string, embeddings [][]float32) (*os.File, error) {
+	header := embedCheckpointHeader{Model: model, Dimensions: dimensions, Inputs: len(inputs), Digest: inputsDigest(inputs)}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(file)
+
+	var stored embedCheckpointHeader
+
+	switch err = decoder.Decode(&stored); {
+	case errors.Is(err, io.EOF):
+		if err = appendJSONLine(file, header); err != nil {
+			file.Close()
+			return nil, err
+		}
+		return file, nil
+	case err != nil:
+		file.Close()
+		return nil, fmt.Errorf("openai: invalid checkpoint file %s: %w", path, err)
+	case stored != header:
+		file.Close()
+		return nil, fmt.Errorf("openai: checkpoint file %s was created for other inputs", path)
+	}
+
+	offset := decoder.InputOffset()
+
+	for {
+		var batch embedCheckpointBatch
+		if err = decoder.Decode(&batch); err != nil {
+			break
+		}
+
+		if len(batch.Indices) != len(batch.Embeddings) {
+			break
+		}
+
+		for j, index := range batch.Indices {
+			if index >= 0 && index < len(embeddings) {
+				embeddings[index] = batch.Embeddings[j]
+			}
+		}
+
+		offset = decoder.InputOffset()
+	}
+
+	// The decoder reads ahead, so the file is positioned after the last complete line explicitly.
+	if err = file.Truncate(offset); err == nil {
+		_, err = file.Seek(offset, io.SeekStart)
+	}
+	if err == nil {
+		_, err = file.Write([]byte("\n"))
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return file, nil
+}
+
+func appendJSONLine(file *os.File, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(append(data, '\n'))
+
+	return err
+}
+
+func inputsDigest(inputs []string) string {
+	hash := sha256.New()
+
+	for _, input := range inputs {
+		_, _ = fmt.Fprintf(hash, "%d:%s", len(input), input)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,138 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+func TestClient_EmbedAll(t *testing.T) {
+	t.Parallel()
+
+	var (
+		calls   atomic.Int32
+		failing atomic.Bool
+	)
+
+	failing.Store(true)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+
+		var request EmbeddingRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Error(err)
+			return
+		}
+
+		var resp EmbeddingResponse
+		for i, input := range request.Input {
+			if input == "input 7" && failing.Load() {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":{"message":"invalid input","type":"invalid_request_error"}}`))
+				return
+			}
+
+			var n float32
+			_, _ = fmt.Sscanf(input, "input %g", &n)
+
+			// Embeddings are returned in reverse order to check they are realigned by index.
+			resp.Data = append([]EmbeddingData{{Index: i, Embedding: []float32{n}}}, resp.Data...)
+		}
+
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient("test", WithBaseURL(server.URL))
+
+	inputs := make([]string, 10)
+	for i := range inputs {
+		inputs[i] = fmt.Sprintf("input %d", i)
+	}
+
+	var progress []int
+
+	options := EmbedAllOptions{
+		BatchSize:      3,
+		Concurrency:    1,
+		CheckpointPath: filepath.Join(t.TempDir(), "checkpoint.jsonl"),
+		Progress: func(p EmbedAllProgress) {
+			progress = append(progress, p.Done)
+		},
+	}
+
+	_, err := client.EmbedAll(context.Background(), "ada", inputs, options)
+
+	var apiErr Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected a 400 error, got %v", err)
+	}
+
+	failing.Store(false)
+	calls.Store(0)
+
+	embeddings, err := client.EmbedAll(context.Background(), "ada", inputs, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("expected the 2 remaining batches to be embedded, got %d calls", got)
+	}
+
+	if want := []int{3, 6, 9, 10}; fmt.Sprint(progress) != fmt.Sprint(want) {
+		t.Fatalf("expected progress to be %v, got %v", want, progress)
+	}
+
+	for i, embedding := range embeddings {
+		if len(embedding) != 1 || embedding[0] != float32(i) {
+			t.Fatalf("expected embedding %d to be [%d], got %v", i, i, embedding)
+		}
+	}
+
+	if _, err = client.EmbedAll(context.Background(), "ada", inputs[1:], options); err == nil {
+		t.Fatal("expected an error for a checkpoint of other inputs")
+	}
+}
+
+func TestClient_EmbedAll_NoRetries(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient("test", WithBaseURL(server.URL))
+
+	if _, err := client.EmbedAll(context.Background(), "ada", []string{"test"}, EmbedAllOptions{MaxRetries: Int(0)}); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("expected retries to be disabled, got %d calls", got)
+	}
+}
+
+func TestEmbedBatches(t *testing.T) {
+	t.Parallel()
+
+	inputs := []string{"a", "b", "c", "a long input of many tokens", "d"}
+
+	got := embedBatches(inputs, []int{0, 1, 2, 3, 4}, 2, 4)
+	want := [][]int{{0, 1}, {2}, {3}, {4}}
+
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("expected batches to be %v, got %v", want, got)
+	}
+}
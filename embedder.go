@@ -0,0 +1,20 @@
+package openai
+
+import "context"
+
+// Embedder creates embeddings with a model, e.g. for vector.Index.
+type Embedder struct {
+	client  *Client
+	model   string
+	options EmbedAllOptions
+}
+
+// NewEmbedder creates an Embedder calling EmbedAll with the model and options.
+func NewEmbedder(client *Client, model string, options EmbedAllOptions) *Embedder {
+	return &Embedder{client: client, model: model, options: options}
+}
+
+// Embed returns the embeddings of the texts, aligned with them.
+func (e *Embedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return e.client.EmbedAll(ctx, e.model, texts, e.options)
+}
@@ -0,0 +1,62 @@
+package openai
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// UnmarshalJSON decodes the embedding from a JSON array or, with EmbeddingEncodingFormatBase64, a base64 string.
+func (d *EmbeddingData) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Index     int             `json:"index"`
+		Object    string          `json:"object"`
+		Embedding json.RawMessage `json:"embedding"`
+	}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	d.Index, d.Object, d.Embedding = raw.Index, raw.Object, nil
+
+	if !bytes.HasPrefix(raw.Embedding, []byte(`"`)) {
+		return json.Unmarshal(raw.Embedding, &d.Embedding)
+	}
+
+	var encoded string
+	if err := json.Unmarshal(raw.Embedding, &encoded); err != nil {
+		return err
+	}
+
+	embedding, err := decodeEmbedding(encoded)
+	if err != nil {
+		return err
+	}
+
+	d.Embedding = embedding
+
+	return nil
+}
+
+// decodeEmbedding decodes base64-encoded little-endian float32 values.
+func decodeEmbedding(encoded string) ([]float32, error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("openai: invalid base64 embedding: %w", err)
+	}
+
+	if len(data)%4 != 0 {
+		return nil, fmt.Errorf("openai: invalid base64 embedding of %d bytes", len(data))
+	}
+
+	embedding := make([]float32, len(data)/4)
+	for i := range embedding {
+		embedding[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
+	}
+
+	return embedding, nil
+}
@@ -0,0 +1,247 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/KirillMironov/openai/internal/tokens"
+)
+
+const (
+	defaultEmbeddingBatchWindow = 10 * time.Millisecond
+	maxEmbeddingBatchSize       = 2048
+	defaultEmbeddingBatchTokens = 100_000
+)
+
+// EmbeddingBatcherConfig configures an EmbeddingBatcher. Zero values are replaced by the defaults.
+type EmbeddingBatcherConfig struct {
+	// Window is how long the first call of a batch waits for other calls to join it. Defaults to 10 milliseconds.
+	Window time.Duration
+	// MaxBatchSize is the number of inputs sending the batch right away. Defaults to 2048, the API limit.
+	MaxBatchSize int
+	// MaxBatchTokens is the estimated number of input tokens sending the batch right away. Defaults to 100000.
+	MaxBatchTokens int
+}
+
+// EmbeddingBatcher coalesces concurrent single-input Embedding calls with the same parameters
+// into one API call, cutting the number of requests counted against the rate limits.
+type EmbeddingBatcher struct {
+	client *Client
+	config EmbeddingBatcherConfig
+
+	mu      sync.Mutex
+	pending map[embeddingBatchKey]*embeddingBatch
+}
+
+type embeddingBatchKey struct {
+	model          string
+	user           string
+	encodingFormat EmbeddingEncodingFormat
+	dimensions     int
+}
+
+type embeddingBatch struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	// waiters is the number of callers still waiting for the batch, it is cancelled once they all gave up.
+	waiters int
+
+	key     embeddingBatchKey
+	inputs  []string
+	tokens  int
+	callers []chan embeddingResult
+	timer   *time.Timer
+}
+
+type embeddingResult struct {
+	resp EmbeddingResponse
+	err  error
+}
+
+// NewEmbeddingBatcher creates an EmbeddingBatcher sending the batches with the client.
+func NewEmbeddingBatcher(client *Client, config EmbeddingBatcherConfig) *EmbeddingBatcher {
+	if config.Window <= 0 {
+		config.Window = defaultEmbeddingBatchWindow
+	}
+	if config.MaxBatchSize <= 0 || config.MaxBatchSize > maxEmbeddingBatchSize {
+		config.MaxBatchSize = maxEmbeddingBatchSize
+	}
+	if config.MaxBatchTokens <= 0 {
+		config.MaxBatchTokens = defaultEmbeddingBatchTokens
+	}
+
+	return &EmbeddingBatcher{
+		client:  client,
+		config:  config,
+		pending: make(map[embeddingBatchKey]*embeddingBatch),
+	}
+}
+
+// Embedding is a drop-in replacement of Client.Embedding.
+// Requests with a single input are batched, the response contains their embedding at index 0
+// and the share of the batch usage matching their estimated tokens.
+// Requests with several inputs, extras or request options are sent on their own.
+// A caller giving up does not fail the others, the batch is cancelled once all its callers gave up.
+func (b *EmbeddingBatcher) Embedding(ctx context.Context, request EmbeddingRequest, options ...RequestOption) (EmbeddingResponse, error) {
+	if len(request.Input) != 1 || len(options) > 0 || !request.RequestExtras.empty() {
+		return b.client.Embedding(ctx, request, options...)
+	}
+
+	batch, result := b.add(ctx, embeddingBatchKey{
+		model:          request.Model,
+		user:           request.User,
+		encodingFormat: request.EncodingFormat,
+		dimensions:     request.Dimensions,
+	}, request.Input[0])
+
+	select {
+	case <-ctx.Done():
+		b.leave(batch)
+		return EmbeddingResponse{}, ctx.Err()
+	case r := <-result:
+		return r.resp, r.err
+	}
+}
+
+// add appends the input to the pending batch of the key and returns the batch and the channel its result is sent to.
+func (b *EmbeddingBatcher) add(ctx context.Context, key embeddingBatchKey, input string) (*embeddingBatch, <-chan embeddingResult) {
+	result := make(chan embeddingResult, 1)
+	inputTokens := tokens.Estimate(input)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	batch, ok := b.pending[key]
+	if ok && batch.tokens+inputTokens > b.config.MaxBatchTokens {
+		b.flushLocked(batch)
+		ok = false
+	}
+
+	if !ok {
+		// The batch outlives the caller which started it, but keeps its values such as tracing spans.
+		batchCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+
+		batch = &embeddingBatch{ctx: batchCtx, cancel: cancel, key: key}
+		b.pending[key] = batch
+		batch.timer = time.AfterFunc(b.config.Window, func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+
+			if b.pending[key] == batch {
+				b.flushLocked(batch)
+			}
+		})
+	}
+
+	batch.inputs = append(batch.inputs, input)
+	batch.tokens += inputTokens
+	batch.callers = append(batch.callers, result)
+	batch.waiters++
+
+	if len(batch.inputs) >= b.config.MaxBatchSize || batch.tokens >= b.config.MaxBatchTokens {
+		b.flushLocked(batch)
+	}
+
+	return batch, result
+}
+
+// leave removes a waiter from the batch, cancelling it if no one waits for it anymore.
+// A batch whose callers all gave up before it was sent is dropped.
+func (b *EmbeddingBatcher) leave(batch *embeddingBatch) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if batch.waiters--; batch.waiters > 0 {
+		return
+	}
+
+	batch.cancel()
+
+	if b.pending[batch.key] == batch {
+		batch.timer.Stop()
+		delete(b.pending, batch.key)
+	}
+}
+
+// flushLocked removes the batch from the pending ones and sends it. b.mu must be held.
+func (b *EmbeddingBatcher) flushLocked(batch *embeddingBatch) {
+	batch.timer.Stop()
+	delete(b.pending, batch.key)
+
+	go b.send(batch)
+}
+
+func (b *EmbeddingBatcher) send(batch *embeddingBatch) {
+	defer batch.cancel()
+
+	resp, err := b.client.Embedding(batch.ctx, EmbeddingRequest{
+		Model:          batch.key.model,
+		Input:          batch.inputs,
+		EncodingFormat: batch.key.encodingFormat,
+		Dimensions:     batch.key.dimensions,
+		User:           batch.key.user,
+	})
+	if err == nil && len(resp.Data) != len(batch.inputs) {
+		err = fmt.Errorf("openai: expected %d embeddings, got %d", len(batch.inputs), len(resp.Data))
+	}
+	if err != nil {
+		for _, caller := range batch.callers {
+			caller <- embeddingResult{err: err}
+		}
+		return
+	}
+
+	embeddings := make([][]float32, len(batch.inputs))
+	for _, data := range resp.Data {
+		if data.Index >= 0 && data.Index < len(embeddings) {
+			embeddings[data.Index] = data.Embedding
+		}
+	}
+
+	usage := splitUsage(resp.Usage, batch.inputs)
+
+	for i, caller := range batch.callers {
+		caller <- embeddingResult{resp: EmbeddingResponse{
+			Object:         resp.Object,
+			Model:          resp.Model,
+			Data:           []EmbeddingData{{Index: 0, Object: "embedding", Embedding: embeddings[i]}},
+			Usage:          usage[i],
+			ResponseExtras: resp.ResponseExtras,
+		}}
+	}
+}
+
+// splitUsage splits the usage of a batch across its inputs in proportion to their estimated tokens.
+// The last input gets the rounding remainder so the shares add up to the batch usage.
+func splitUsage(usage Usage, inputs []string) []Usage {
+	var (
+		estimates = make([]int, len(inputs))
+		total     int
+	)
+
+	for i, input := range inputs {
+		estimates[i] = tokens.Estimate(input)
+		total += estimates[i]
+	}
+
+	shares := make([]Usage, len(inputs))
+	remaining := usage.PromptTokens
+
+	for i := range inputs {
+		share := remaining
+		if i < len(inputs)-1 {
+			if total > 0 {
+				share = usage.PromptTokens * estimates[i] / total
+			} else {
+				share = usage.PromptTokens / len(inputs)
+			}
+		}
+		remaining -= share
+
+		shares[i] = Usage{PromptTokens: share, TotalTokens: share}
+	}
+
+	return shares
+}
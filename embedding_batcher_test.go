@@ -0,0 +1,108 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEmbeddingBatcher(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+
+		var request EmbeddingRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Error(err)
+			return
+		}
+
+		var resp EmbeddingResponse
+		for i, input := range request.Input {
+			resp.Data = append(resp.Data, EmbeddingData{Index: i, Embedding: []float32{float32(len(input))}})
+			resp.Usage.PromptTokens += 10
+		}
+		resp.Usage.TotalTokens = resp.Usage.PromptTokens
+
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient("test", WithBaseURL(server.URL))
+	batcher := NewEmbeddingBatcher(client, EmbeddingBatcherConfig{Window: 50 * time.Millisecond, MaxBatchSize: 4})
+
+	var (
+		wg          sync.WaitGroup
+		promptTotal atomic.Int64
+	)
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func(input string) {
+			defer wg.Done()
+
+			resp, err := batcher.Embedding(context.Background(), EmbeddingRequest{Model: "ada", Input: []string{input}})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			if len(resp.Data) != 1 || resp.Data[0].Embedding[0] != float32(len(input)) {
+				t.Errorf("expected the embedding of %q, got %+v", input, resp.Data)
+			}
+
+			promptTotal.Add(int64(resp.Usage.PromptTokens))
+		}(fmt.Sprintf("input %0*d", i+1, i))
+	}
+
+	wg.Wait()
+
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("expected 2 batched calls, got %d", got)
+	}
+	if got := promptTotal.Load(); got != 80 {
+		t.Fatalf("expected the usage shares to add up to 80 tokens, got %d", got)
+	}
+}
+
+func TestEmbeddingBatcher_Cancel(t *testing.T) {
+	t.Parallel()
+
+	cancelled := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+
+		<-r.Context().Done()
+		close(cancelled)
+	}))
+	t.Cleanup(server.Close)
+
+	batcher := NewEmbeddingBatcher(NewClient("test", WithBaseURL(server.URL)), EmbeddingBatcherConfig{Window: time.Millisecond})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := batcher.Embedding(ctx, EmbeddingRequest{Model: "ada", Input: []string{"test"}})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the batch to be cancelled once its only caller left")
+	}
+}
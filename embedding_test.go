@@ -0,0 +1,84 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestClient_Embedding_Base64(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+			return
+		}
+
+		if body["encoding_format"] != "base64" || body["dimensions"] != float64(2) {
+			t.Errorf("expected base64 encoding and 2 dimensions, got %v", body)
+		}
+
+		// [1.5, -2] as little-endian float32 values.
+		_, _ = w.Write([]byte(`{"data":[{"index":0,"object":"embedding","embedding":"AADAPwAAAMA="}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient("test", WithBaseURL(server.URL))
+
+	resp, err := client.Embedding(context.Background(), EmbeddingRequest{
+		Model:          "text-embedding-3-small",
+		Input:          []string{"test"},
+		EncodingFormat: EmbeddingEncodingFormatBase64,
+		Dimensions:     2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := resp.Data[0].Embedding; len(got) != 2 || got[0] != 1.5 || got[1] != -2 {
+		t.Fatalf("expected embedding to be [1.5 -2], got %v", got)
+	}
+}
+
+func TestEmbeddingData_UnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		data    string
+		want    []float32
+		wantErr bool
+	}{
+		{name: "float", data: `{"index":1,"embedding":[0.25,-1]}`, want: []float32{0.25, -1}},
+		{name: "base64", data: `{"index":1,"embedding":"AACAPg=="}`, want: []float32{0.25}},
+		{name: "invalid base64", data: `{"index":1,"embedding":"!"}`, wantErr: true},
+		{name: "truncated base64", data: `{"index":1,"embedding":"AACA"}`, wantErr: true},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var data EmbeddingData
+
+			err := json.Unmarshal([]byte(tc.data), &data)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("json.Unmarshal() error = %v, wantErr = %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+
+			if data.Index != 1 || !reflect.DeepEqual(data.Embedding, tc.want) {
+				t.Fatalf("expected embedding %v at index 1, got %+v", tc.want, data)
+			}
+		})
+	}
+}
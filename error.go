@@ -6,6 +6,7 @@ type Error struct {
 	StatusCode int    `json:"-"`
 	Message    string `json:"message"`
 	Type       string `json:"type"`
+	Code       string `json:"code"`
 }
 
 func (e Error) Error() string {
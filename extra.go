@@ -0,0 +1,146 @@
+package openai
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// RequestExtras carries request parameters that are not modeled by this package yet.
+// ExtraBody fields are merged into the JSON or multipart payload and take precedence over fields with the same name.
+// Endpoints without a payload take extra query parameters and headers through WithQuery and WithHeader.
+type RequestExtras struct {
+	ExtraBody    map[string]any
+	ExtraHeaders http.Header
+	ExtraQuery   url.Values
+}
+
+func (e RequestExtras) requestExtras() RequestExtras {
+	return e
+}
+
+func (e RequestExtras) empty() bool {
+	return len(e.ExtraBody) == 0 && len(e.ExtraHeaders) == 0 && len(e.ExtraQuery) == 0
+}
+
+// ResponseExtras captures response fields that are not modeled by this package yet.
+// Extra maps the names of unknown top-level fields to their raw JSON values.
+type ResponseExtras struct {
+	Extra map[string]json.RawMessage
+}
+
+func (e *ResponseExtras) setExtra(extra map[string]json.RawMessage) {
+	e.Extra = extra
+}
+
+type requestExtrasCarrier interface {
+	requestExtras() RequestExtras
+}
+
+type responseExtrasSetter interface {
+	setExtra(map[string]json.RawMessage)
+}
+
+func extrasOf(payload any) RequestExtras {
+	if carrier, ok := payload.(requestExtrasCarrier); ok {
+		return carrier.requestExtras()
+	}
+
+	return RequestExtras{}
+}
+
+// mergeJSON adds the extra fields to the given JSON object.
+func mergeJSON(data []byte, extra map[string]any) ([]byte, error) {
+	if len(extra) == 0 {
+		return data, nil
+	}
+
+	var fields map[string]json.RawMessage
+
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	for name, value := range extra {
+		field, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+
+		fields[name] = field
+	}
+
+	return json.Marshal(fields)
+}
+
+// unmarshalResponse decodes data into target and, if target embeds ResponseExtras, collects the unknown fields.
+func unmarshalResponse(data []byte, target any) error {
+	if err := json.Unmarshal(data, target); err != nil {
+		return err
+	}
+
+	setter, ok := target.(responseExtrasSetter)
+	if !ok {
+		return nil
+	}
+
+	var fields map[string]json.RawMessage
+
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil
+	}
+
+	known := knownJSONFields(reflect.TypeOf(target).Elem())
+
+	for name := range fields {
+		if _, ok = known[name]; ok {
+			delete(fields, name)
+		}
+	}
+
+	if len(fields) > 0 {
+		setter.setExtra(fields)
+	}
+
+	return nil
+}
+
+var knownJSONFieldsCache sync.Map // map[reflect.Type]map[string]struct{}
+
+// knownJSONFields returns the JSON field names of the given struct type, including promoted fields.
+func knownJSONFields(t reflect.Type) map[string]struct{} {
+	if known, ok := knownJSONFieldsCache.Load(t); ok {
+		return known.(map[string]struct{})
+	}
+
+	known := make(map[string]struct{})
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			for embedded := range knownJSONFields(field.Type) {
+				known[embedded] = struct{}{}
+			}
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		known[name] = struct{}{}
+	}
+
+	knownJSONFieldsCache.Store(t, known)
+
+	return known
+}
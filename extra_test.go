@@ -0,0 +1,75 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestClient_Extras(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+
+		if got, want := body["seed"], float64(42); got != want {
+			t.Errorf("expected seed to be %v, got %v", want, got)
+		}
+		if got, want := r.Header.Get("X-Feature"), "beta"; got != want {
+			t.Errorf("expected X-Feature header to be %s, got %s", want, got)
+		}
+		if got, want := r.URL.Query().Get("debug"), "true"; got != want {
+			t.Errorf("expected debug query param to be %s, got %s", want, got)
+		}
+
+		_, _ = w.Write([]byte(`{"id":"chatcmpl-123","model":"gpt-4","system_fingerprint":"fp_44709d6fcb"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient("test", WithBaseURL(server.URL))
+
+	resp, err := client.ChatCompletion(context.Background(), ChatCompletionRequest{
+		Model: "gpt-4",
+		RequestExtras: RequestExtras{
+			ExtraBody:    map[string]any{"seed": 42},
+			ExtraHeaders: http.Header{"X-Feature": {"beta"}},
+			ExtraQuery:   url.Values{"debug": {"true"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(resp.Extra) != 1 {
+		t.Fatalf("expected 1 extra field, got %d", len(resp.Extra))
+	}
+	if got, want := string(resp.Extra["system_fingerprint"]), `"fp_44709d6fcb"`; got != want {
+		t.Fatalf("expected system_fingerprint to be %s, got %s", want, got)
+	}
+}
+
+func TestClient_WithQuery(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.URL.Query().Get("purpose"), "fine-tune"; got != want {
+			t.Errorf("expected purpose query param to be %s, got %s", want, got)
+		}
+
+		_, _ = w.Write([]byte(`{"object":"list","data":[]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient("test", WithBaseURL(server.URL))
+
+	if _, err := client.Files(context.Background(), WithQuery("purpose", "fine-tune")); err != nil {
+		t.Fatal(err)
+	}
+}
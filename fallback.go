@@ -0,0 +1,102 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+)
+
+type ErrorClass string
+
+const (
+	// ErrorClassContextLength is returned for prompts exceeding the model context window.
+	ErrorClassContextLength ErrorClass = "context_length"
+	// ErrorClassRateLimited is returned for 429 Too Many Requests responses.
+	ErrorClassRateLimited ErrorClass = "rate_limited"
+	// ErrorClassServer is returned for 5xx responses.
+	ErrorClassServer ErrorClass = "server"
+	// ErrorClassTimeout is returned for calls exceeding their timeout.
+	// Only the timeout set by WithTimeout leads to a fallback, as a call whose context expired leaves no time for other models.
+	ErrorClassTimeout ErrorClass = "timeout"
+)
+
+// ClassifyError returns the class of the error returned by a Client method,
+// or an empty string if the error has none of the known classes.
+func ClassifyError(err error) ErrorClass {
+	var apiErr Error
+
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.Code == "context_length_exceeded" || strings.Contains(apiErr.Message, "maximum context length"):
+			return ErrorClassContextLength
+		case apiErr.StatusCode == http.StatusTooManyRequests:
+			return ErrorClassRateLimited
+		case apiErr.StatusCode >= http.StatusInternalServerError:
+			return ErrorClassServer
+		}
+
+		return ""
+	}
+
+	var netErr net.Error
+
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return ErrorClassTimeout
+	}
+
+	return ""
+}
+
+// FallbackPolicy maps a model to the alternative models to try, in order,
+// when a call with it fails with an error of the given class.
+//
+//	openai.FallbackPolicy{
+//		"gpt-4": {
+//			openai.ErrorClassContextLength: {"gpt-4-32k"},
+//			openai.ErrorClassRateLimited:   {"gpt-4-0613", "gpt-3.5-turbo"},
+//		},
+//	}
+type FallbackPolicy map[string]map[ErrorClass][]string
+
+// WithFallback retries failed ChatCompletion and Completion calls with alternative models.
+// The model which answered is reported in the AnsweredBy field of the response.
+func WithFallback(policy FallbackPolicy) ClientOption {
+	return func(c *Client) {
+		c.fallback = policy
+	}
+}
+
+// withFallback calls call with the model and, if it fails with an error the policy of the client has alternatives for,
+// with the alternative models until one of them succeeds.
+// The timeout set by WithRequestTimeout covers all the calls, instead of restarting for every model.
+// It returns the response, the model which answered and the error of the last call.
+func withFallback[T any](ctx context.Context, client *Client, model string, options []RequestOption, call func(ctx context.Context, model string) (T, error)) (T, string, error) {
+	ctx, cancel := newRequestOptions(client, options).context(ctx)
+	defer cancel()
+
+	resp, err := call(ctx, model)
+	if err == nil {
+		return resp, model, nil
+	}
+
+	alternatives := client.fallback[model][ClassifyError(err)]
+
+	for _, alternative := range alternatives {
+		// The caller gave up or the timeout expired, there is no point in trying other models.
+		if ctx.Err() != nil {
+			break
+		}
+
+		if resp, err = call(ctx, alternative); err == nil {
+			return resp, alternative, nil
+		}
+
+		if ClassifyError(err) == "" {
+			break
+		}
+	}
+
+	return resp, "", err
+}
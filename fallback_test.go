@@ -0,0 +1,120 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClient_WithFallback(t *testing.T) {
+	t.Parallel()
+
+	var models []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request ChatCompletionRequest
+
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Error(err)
+		}
+
+		models = append(models, request.Model)
+
+		switch request.Model {
+		case "gpt-4":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"message":"This model's maximum context length is 8192 tokens",` +
+				`"type":"invalid_request_error","code":"context_length_exceeded"}}`))
+		case "gpt-4-32k":
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error":{"message":"Rate limit reached","type":"requests","code":null}}`))
+		default:
+			_, _ = w.Write([]byte(`{"model":"` + request.Model + `"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient("test", WithBaseURL(server.URL), WithFallback(FallbackPolicy{
+		"gpt-4": {
+			ErrorClassContextLength: {"gpt-4-32k", "gpt-3.5-turbo-16k"},
+			ErrorClassRateLimited:   {"gpt-3.5-turbo"},
+		},
+	}))
+
+	resp, err := client.ChatCompletion(context.Background(), ChatCompletionRequest{Model: "gpt-4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := resp.AnsweredBy, "gpt-3.5-turbo-16k"; got != want {
+		t.Fatalf("expected response to be answered by %s, got %s", want, got)
+	}
+
+	want := []string{"gpt-4", "gpt-4-32k", "gpt-3.5-turbo-16k"}
+	if !reflect.DeepEqual(models, want) {
+		t.Fatalf("expected models %v to be tried, got %v", want, models)
+	}
+}
+
+func TestClient_WithFallback_Timeout(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request ChatCompletionRequest
+
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Error(err)
+		}
+
+		select {
+		case <-time.After(150 * time.Millisecond):
+		case <-r.Context().Done():
+			return
+		}
+
+		if request.Model == "gpt-4" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error":{"message":"Rate limit reached","type":"requests","code":null}}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"model":"` + request.Model + `"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient("test", WithBaseURL(server.URL), WithFallback(FallbackPolicy{
+		"gpt-4": {ErrorClassRateLimited: {"gpt-3.5-turbo"}},
+	}))
+
+	_, err := client.ChatCompletion(context.Background(), ChatCompletionRequest{Model: "gpt-4"},
+		WithRequestTimeout(200*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the timeout to cover all models, got %v", err)
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		err  error
+		want ErrorClass
+	}{
+		{err: Error{StatusCode: http.StatusBadRequest, Code: "context_length_exceeded"}, want: ErrorClassContextLength},
+		{err: Error{StatusCode: http.StatusTooManyRequests}, want: ErrorClassRateLimited},
+		{err: Error{StatusCode: http.StatusBadGateway}, want: ErrorClassServer},
+		{err: context.DeadlineExceeded, want: ErrorClassTimeout},
+		{err: Error{StatusCode: http.StatusUnauthorized}, want: ""},
+	}
+
+	for _, tc := range tests {
+		if got := ClassifyError(tc.err); got != tc.want {
+			t.Errorf("ClassifyError(%v) = %q, want %q", tc.err, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package openai
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	hedgingLatencySamples    = 512
+	hedgingMinLatencySamples = 20
+)
+
+// HedgingConfig configures a Hedger. Zero values are replaced by the defaults.
+type HedgingConfig struct {
+	// Operations are the hedged operations. Defaults to chat completions and embeddings.
+	Operations []string
+	// Percentile of the observed latencies of an operation after which a hedge is sent. Defaults to 0.95.
+	Percentile float64
+	// InitialDelay is used until enough latencies are observed. Defaults to 1 second.
+	InitialDelay time.Duration
+	// MaxExtraLoad caps the hedges to this share of the hedged operation calls. Defaults to 0.05.
+	MaxExtraLoad float64
+}
+
+// HedgingStats counts the calls handled by a Hedger.
+type HedgingStats struct {
+	Requests int64
+	Hedges   int64
+	// HedgeWins is the number of hedges which returned before the original request.
+	HedgeWins int64
+}
+
+// Hedger reduces tail latency by sending a duplicate of a slow request and taking whichever response
+// arrives first. The other request is cancelled.
+// Hedged requests are billed twice, so the share of hedges is capped.
+type Hedger struct {
+	config     HedgingConfig
+	operations map[string]bool
+
+	requests  atomic.Int64
+	hedges    atomic.Int64
+	hedgeWins atomic.Int64
+
+	mu        sync.Mutex
+	latencies map[string]*latencyWindow
+}
+
+// NewHedger creates a Hedger with the given configuration.
+func NewHedger(config HedgingConfig) *Hedger {
+	if len(config.Operations) == 0 {
+		config.Operations = []string{OperationChatCompletions, OperationEmbeddings}
+	}
+	if config.Percentile <= 0 || config.Percentile > 1 {
+		config.Percentile = 0.95
+	}
+	if config.InitialDelay <= 0 {
+		config.InitialDelay = time.Second
+	}
+	if config.MaxExtraLoad <= 0 {
+		config.MaxExtraLoad = 0.05
+	}
+
+	operations := make(map[string]bool, len(config.Operations))
+	for _, operation := range config.Operations {
+		operations[operation] = true
+	}
+
+	return &Hedger{
+		config:     config,
+		operations: operations,
+		latencies:  make(map[string]*latencyWindow),
+	}
+}
+
+// Stats returns the number of hedged operation calls, sent hedges and hedges which won.
+func (h *Hedger) Stats() HedgingStats {
+	return HedgingStats{
+		Requests:  h.requests.Load(),
+		Hedges:    h.hedges.Load(),
+		HedgeWins: h.hedgeWins.Load(),
+	}
+}
+
+// WithHedging hedges the configured operations of the Client.
+func WithHedging(hedger *Hedger) ClientOption {
+	return WithMiddleware(HedgingMiddleware(hedger))
+}
+
+// HedgingMiddleware hedges the configured operations.
+func HedgingMiddleware(hedger *Hedger) Middleware {
+	return func(next Handler) Handler {
+		return func(op Operation, req *http.Request) (*http.Response, error) {
+			if !hedger.operations[op.Name] {
+				return next(op, req)
+			}
+
+			return hedger.do(op, req, next)
+		}
+	}
+}
+
+type hedgeResult struct {
+	resp  *http.Response
+	err   error
+	hedge bool
+	// attempt is the index of the request, 0 for the original one.
+	attempt int
+	cancel  context.CancelFunc
+}
+
+func (h *Hedger) do(op Operation, req *http.Request, next Handler) (*http.Response, error) {
+	h.requests.Add(1)
+
+	var (
+		results = make(chan hedgeResult, 2)
+		cancels []context.CancelFunc
+	)
+
+	send := func(hedge bool) error {
+		attemptReq, err := rewindRequest(req)
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithCancel(req.Context())
+		attempt := len(cancels)
+		cancels = append(cancels, cancel)
+		start := time.Now()
+
+		go func() {
+			resp, err := next(op, attemptReq.WithContext(ctx))
+			if err == nil && !isRetryableStatus(resp.StatusCode) {
+				h.observe(op.Name, time.Since(start))
+			}
+			results <- hedgeResult{resp: resp, err: err, hedge: hedge, attempt: attempt, cancel: cancel}
+		}()
+
+		return nil
+	}
+
+	if err := send(false); err != nil {
+		return nil, err
+	}
+
+	timer := time.NewTimer(h.delay(op.Name))
+	defer timer.Stop()
+
+	var (
+		inFlight = 1
+		last     hedgeResult
+	)
+
+	for inFlight > 0 {
+		select {
+		case <-timer.C:
+			if inFlight == 1 && h.allowHedge() {
+				if err := send(true); err == nil {
+					h.hedges.Add(1)
+					inFlight++
+				}
+			}
+		case result := <-results:
+			inFlight--
+
+			if result.err == nil && !isRetryableStatus(result.resp.StatusCode) {
+				if result.hedge {
+					h.hedgeWins.Add(1)
+				}
+
+				// The losing request is cancelled right away, its response is released in the background.
+				for i, cancel := range cancels {
+					if i != result.attempt {
+						cancel()
+					}
+				}
+				discard(results, inFlight)
+
+				if last.cancel != nil {
+					closeResult(last)
+				}
+
+				// The winner context is cancelled once the caller is done with the response body.
+				result.resp.Body = &cancelOnClose{ReadCloser: result.resp.Body, cancel: result.cancel}
+
+				return result.resp, nil
+			}
+
+			if last.cancel != nil {
+				closeResult(last)
+			}
+			last = result
+		}
+	}
+
+	// Both requests failed, return the last failure as is.
+	if last.err == nil {
+		last.resp.Body = &cancelOnClose{ReadCloser: last.resp.Body, cancel: last.cancel}
+	} else {
+		last.cancel()
+	}
+
+	return last.resp, last.err
+}
+
+// allowHedge reports whether sending another hedge keeps the hedges within the extra load cap.
+func (h *Hedger) allowHedge() bool {
+	return float64(h.hedges.Load()+1) <= h.config.MaxExtraLoad*float64(h.requests.Load())
+}
+
+// delay returns the configured percentile of the operation latencies.
+func (h *Hedger) delay(operation string) time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	window, ok := h.latencies[operation]
+	if !ok || len(window.samples) < hedgingMinLatencySamples {
+		return h.config.InitialDelay
+	}
+
+	return window.percentile(h.config.Percentile)
+}
+
+func (h *Hedger) observe(operation string, latency time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	window, ok := h.latencies[operation]
+	if !ok {
+		window = new(latencyWindow)
+		h.latencies[operation] = window
+	}
+
+	window.add(latency)
+}
+
+// discard cancels the remaining in-flight requests and releases their responses.
+func discard(results <-chan hedgeResult, inFlight int) {
+	if inFlight == 0 {
+		return
+	}
+
+	go func() {
+		for i := 0; i < inFlight; i++ {
+			closeResult(<-results)
+		}
+	}()
+}
+
+func closeResult(result hedgeResult) {
+	result.cancel()
+
+	if result.resp != nil {
+		_, _ = io.Copy(io.Discard, result.resp.Body)
+		result.resp.Body.Close()
+	}
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
+
+// latencyWindow keeps the most recent latency samples.
+type latencyWindow struct {
+	samples []time.Duration
+	next    int
+}
+
+func (w *latencyWindow) add(latency time.Duration) {
+	if len(w.samples) < hedgingLatencySamples {
+		w.samples = append(w.samples, latency)
+		return
+	}
+
+	w.samples[w.next] = latency
+	w.next = (w.next + 1) % hedgingLatencySamples
+}
+
+func (w *latencyWindow) percentile(p float64) time.Duration {
+	sorted := make([]time.Duration, len(w.samples))
+	copy(sorted, w.samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	return sorted[int(p*float64(len(sorted)-1))]
+}
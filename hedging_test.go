@@ -0,0 +1,117 @@
+package openai
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_WithHedging(t *testing.T) {
+	t.Parallel()
+
+	var (
+		calls     atomic.Int32
+		cancelled = make(chan struct{})
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+
+		if calls.Add(1) == 1 {
+			select {
+			case <-r.Context().Done():
+				close(cancelled)
+			case <-time.After(5 * time.Second):
+				t.Error("expected the slow request to be cancelled")
+			}
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"data":[{"index":0,"embedding":[0.5]}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	hedger := NewHedger(HedgingConfig{InitialDelay: 20 * time.Millisecond, MaxExtraLoad: 1})
+
+	client := NewClient("test", WithBaseURL(server.URL), WithHedging(hedger))
+
+	resp, err := client.Embedding(context.Background(), EmbeddingRequest{Model: "ada", Input: []string{"test"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(resp.Data) != 1 {
+		t.Fatalf("expected 1 embedding, got %d", len(resp.Data))
+	}
+
+	if got, want := hedger.Stats(), (HedgingStats{Requests: 1, Hedges: 1, HedgeWins: 1}); got != want {
+		t.Fatalf("expected stats to be %+v, got %+v", want, got)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the slow request to be cancelled")
+	}
+}
+
+// closeRecorder records whether the response body was closed.
+type closeRecorder struct {
+	io.ReadCloser
+	closed *atomic.Bool
+}
+
+func (r closeRecorder) Close() error {
+	r.closed.Store(true)
+	return r.ReadCloser.Close()
+}
+
+func TestClient_WithHedging_FailedOriginal(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			time.Sleep(40 * time.Millisecond)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		time.Sleep(80 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"data":[{"index":0,"embedding":[0.5]}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	var failedClosed atomic.Bool
+
+	recordFailedClose := func(next Handler) Handler {
+		return func(op Operation, req *http.Request) (*http.Response, error) {
+			resp, err := next(op, req)
+			if err == nil && resp.StatusCode == http.StatusServiceUnavailable {
+				resp.Body = closeRecorder{ReadCloser: resp.Body, closed: &failedClosed}
+			}
+			return resp, err
+		}
+	}
+
+	hedger := NewHedger(HedgingConfig{InitialDelay: 10 * time.Millisecond, MaxExtraLoad: 1})
+
+	client := NewClient("test", WithBaseURL(server.URL), WithHedging(hedger), WithMiddleware(recordFailedClose))
+
+	if _, err := client.Embedding(context.Background(), EmbeddingRequest{Model: "ada", Input: []string{"test"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := hedger.Stats(), (HedgingStats{Requests: 1, Hedges: 1, HedgeWins: 1}); got != want {
+		t.Fatalf("expected stats to be %+v, got %+v", want, got)
+	}
+
+	if !failedClosed.Load() {
+		t.Fatal("expected the failed original response to be closed")
+	}
+}
@@ -10,59 +10,139 @@ import (
 	"github.com/KirillMironov/openai/internal/formdata"
 )
 
-func makeJSONRequest[T any](ctx context.Context, client *Client, method, path string, payload any) (T, error) {
+func makeJSONRequest[T any](ctx context.Context, client *Client, operation, method, path string, payload any, options []RequestOption) (T, error) {
 	var (
 		target T
 		body   io.Reader
+		op     = newOperation(operation, payload)
+		extras = extrasOf(payload)
+		opts   = newRequestOptions(client, options)
 	)
 
 	if payload != nil {
-		buf := new(bytes.Buffer)
-		if err := json.NewEncoder(buf).Encode(payload); err != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
 			return target, err
 		}
 
-		body = buf
+		if data, err = mergeJSON(data, extras.ExtraBody); err != nil {
+			return target, err
+		}
+
+		body = bytes.NewReader(data)
+	}
+
+	endpoint, err := client.url(opts.baseURL, op, path)
+	if err != nil {
+		return target, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, client.baseURL+path, body)
+	ctx, cancel := opts.context(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
 	if err != nil {
 		return target, err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 
-	return makeRequest[T](client, req)
+	return makeRequest[T](client, op, req, extras, opts)
 }
 
-func makeFormDataRequest[T any](ctx context.Context, client *Client, path string, payload any) (T, error) {
-	var target T
+func makeFormDataRequest[T any](ctx context.Context, client *Client, operation, path string, payload any, options []RequestOption) (T, error) {
+	var (
+		target T
+		op     = newOperation(operation, payload)
+		extras = extrasOf(payload)
+		opts   = newRequestOptions(client, options)
+	)
+
+	data, contentType, err := formdata.MarshalExtra(payload, extras.ExtraBody)
+	if err != nil {
+		return target, err
+	}
 
-	data, contentType, err := formdata.Marshal(payload)
+	endpoint, err := client.url(opts.baseURL, op, path)
 	if err != nil {
 		return target, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, client.baseURL+path, bytes.NewReader(data))
+	ctx, cancel := opts.context(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
 	if err != nil {
 		return target, err
 	}
 
 	req.Header.Set("Content-Type", contentType)
 
-	return makeRequest[T](client, req)
+	return makeRequest[T](client, op, req, extras, opts)
 }
 
-func makeRequest[T any](client *Client, req *http.Request) (T, error) {
+func makeRequest[T any](client *Client, op Operation, req *http.Request, extras RequestExtras, opts requestOptions) (T, error) {
 	var target T
 
-	req.Header.Set("Authorization", "Bearer "+client.apiKey)
+	apiKey, err := client.authorize(req)
+	if err != nil {
+		return target, err
+	}
 
-	if client.organization != "" {
-		req.Header.Set("OpenAI-Organization", client.organization)
+	for key, values := range extras.ExtraHeaders {
+		req.Header[http.CanonicalHeaderKey(key)] = values
 	}
 
-	resp, err := client.httpClient.Do(req)
+	if len(extras.ExtraQuery) > 0 || len(opts.query) > 0 {
+		query := req.URL.Query()
+		for key, values := range extras.ExtraQuery {
+			query[key] = values
+		}
+		for key, values := range opts.query {
+			query[key] = values
+		}
+		req.URL.RawQuery = query.Encode()
+	}
+
+	for key, values := range opts.header {
+		req.Header[key] = values
+	}
+
+	handler := chain(func(_ Operation, req *http.Request) (*http.Response, error) {
+		send := func(req *http.Request) (*http.Response, error) {
+			return doWithRetries(client.httpClient, req, opts.maxRetries)
+		}
+
+		if client.balancer != nil && opts.baseURL == client.baseURL {
+			return client.balancer.do(req, client.baseURL, send)
+		}
+
+		return send(req)
+	}, client.middlewares)
+
+	// The exact cache is checked first, as it needs no embedding.
+	if client.semanticCache != nil {
+		handler = client.semanticCache.wrap(handler, opts.noCache)
+	}
+	if client.cache != nil {
+		handler = client.cache.wrap(handler, opts.noCache)
+	}
+
+	resp, err := handler(op, req)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized && apiKey != "" &&
+		client.refreshCredential(req.Context(), apiKey) {
+		resp.Body.Close()
+
+		if req, err = rewindRequest(req); err != nil {
+			return target, err
+		}
+
+		if _, err = client.authorize(req); err != nil {
+			return target, err
+		}
+
+		resp, err = handler(op, req)
+	}
 	if err != nil {
 		return target, err
 	}
@@ -76,8 +156,9 @@ func makeRequest[T any](client *Client, req *http.Request) (T, error) {
 	if resp.StatusCode != http.StatusOK {
 		var openaiApiResponse struct {
 			Error struct {
-				Message string `json:"message"`
-				Type    string `json:"type"`
+				Message string          `json:"message"`
+				Type    string          `json:"type"`
+				Code    json.RawMessage `json:"code"`
 			} `json:"error"`
 		}
 
@@ -93,8 +174,86 @@ func makeRequest[T any](client *Client, req *http.Request) (T, error) {
 			StatusCode: resp.StatusCode,
 			Message:    openaiApiResponse.Error.Message,
 			Type:       openaiApiResponse.Error.Type,
+			Code:       errorCode(openaiApiResponse.Error.Code),
 		}
 	}
 
-	return target, json.Unmarshal(respData, &target)
+	if err = unmarshalResponse(respData, &target); err != nil {
+		return target, err
+	}
+
+	if setter, ok := any(&target).(cachedSetter); ok && resp.Header.Get(cacheStatusHeader) == cacheStatusHit {
+		setter.setCached()
+	}
+
+	return target, nil
+}
+
+// errorCode returns the error code, which the API sends as a string, a number or null.
+func errorCode(raw json.RawMessage) string {
+	var code string
+
+	if err := json.Unmarshal(raw, &code); err == nil {
+		return code
+	}
+
+	if string(raw) == "null" {
+		return ""
+	}
+
+	return string(raw)
+}
+
+// url returns the URL of the operation endpoint with the given path.
+func (c *Client) url(baseURL string, op Operation, path string) (string, error) {
+	if c.azure != nil {
+		return c.azure.url(baseURL, op, path)
+	}
+
+	return baseURL + path, nil
+}
+
+// authorize sets the authentication headers of the request and returns the API key used, if any.
+func (c *Client) authorize(req *http.Request) (apiKey string, err error) {
+	if c.azure != nil && c.azure.TokenProvider != nil {
+		return "", c.azure.authorizeToken(req)
+	}
+
+	if apiKey, err = c.credential(req.Context()); err != nil {
+		return "", err
+	}
+
+	if c.azure != nil {
+		if apiKey != "" {
+			req.Header.Set("api-key", apiKey)
+		}
+		return apiKey, nil
+	}
+
+	// Without a key, the Authorization header is left for a proxy or the balancer backend to set.
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	if c.organization != "" {
+		req.Header.Set("OpenAI-Organization", c.organization)
+	}
+
+	return apiKey, nil
+}
+
+// rewindRequest returns a copy of the request with a fresh body, so it can be sent again.
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	clone := req.Clone(req.Context())
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+
+		clone.Body = body
+	}
+
+	return clone, nil
 }
@@ -8,6 +8,7 @@ import (
 	"mime/multipart"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -24,6 +25,13 @@ type File interface {
 // The value must be a struct or a pointer to a struct.
 // If the field implements the File interface, the field is marshaled as a file.
 func Marshal(value any) (data []byte, contentType string, err error) {
+	return MarshalExtra(value, nil)
+}
+
+// MarshalExtra is like Marshal, but also encodes the given extra fields after the struct fields.
+// Extra fields are written in the order of their sorted names and follow the same encoding rules as struct fields.
+// Struct fields with the same name as an extra field are skipped, so the extra field replaces them.
+func MarshalExtra(value any, extra map[string]any) (data []byte, contentType string, err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			err = fmt.Errorf("formdata: %v", r)
@@ -47,13 +55,7 @@ func Marshal(value any) (data []byte, contentType string, err error) {
 
 	for i := 0; i < v.NumField(); i++ {
 		field := v.Field(i)
-		tag := t.Field(i).Tag.Get(formTag)
-
-		if strings.Contains(tag, "omitempty") && field.IsZero() {
-			continue
-		}
-
-		tag = strings.Split(tag, ",")[0]
+		tag, options, _ := strings.Cut(t.Field(i).Tag.Get(formTag), ",")
 
 		if tag == "-" {
 			continue
@@ -63,51 +65,29 @@ func Marshal(value any) (data []byte, contentType string, err error) {
 			tag = strings.ToLower(t.Field(i).Name)
 		}
 
-		if field.Type().Implements(reflect.TypeOf((*File)(nil)).Elem()) {
-			if field.IsNil() {
-				continue
-			}
-
-			file := field.Interface().(File)
-
-			filename := filepath.Base(file.Name())
-
-			formFile, err := writer.CreateFormFile(tag, filename)
-			if err != nil {
-				return nil, "", err
-			}
-
-			if _, err = io.Copy(formFile, file); err != nil {
-				return nil, "", err
-			}
-
+		if _, ok := extra[tag]; ok {
 			continue
 		}
 
-		if field.Kind() == reflect.Interface || field.Kind() == reflect.Ptr {
-			field = field.Elem()
+		if strings.Contains(options, "omitempty") && field.IsZero() {
+			continue
 		}
 
-		var fieldValue string
-
-		switch field.Kind() {
-		case reflect.String:
-			fieldValue = field.String()
-		case reflect.Bool:
-			fieldValue = strconv.FormatBool(field.Bool())
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			fieldValue = strconv.FormatInt(field.Int(), 10)
-		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			fieldValue = strconv.FormatUint(field.Uint(), 10)
-		case reflect.Float32:
-			fieldValue = strconv.FormatFloat(field.Float(), 'f', -1, 32)
-		case reflect.Float64:
-			fieldValue = strconv.FormatFloat(field.Float(), 'f', -1, 64)
-		default:
-			return nil, "", fmt.Errorf("formdata: unsupported type: %s", field.Kind())
+		if err = writeField(writer, tag, field); err != nil {
+			return nil, "", err
 		}
+	}
+
+	names := make([]string, 0, len(extra))
+	for name := range extra {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		extraValue := extra[name]
 
-		if err = writer.WriteField(tag, fieldValue); err != nil {
+		if err = writeField(writer, name, reflect.ValueOf(&extraValue).Elem()); err != nil {
 			return nil, "", err
 		}
 	}
@@ -118,3 +98,53 @@ func Marshal(value any) (data []byte, contentType string, err error) {
 
 	return buf.Bytes(), writer.FormDataContentType(), nil
 }
+
+func writeField(writer *multipart.Writer, name string, field reflect.Value) error {
+	if field.Type().Implements(reflect.TypeOf((*File)(nil)).Elem()) {
+		if field.IsNil() {
+			return nil
+		}
+
+		file := field.Interface().(File)
+
+		filename := filepath.Base(file.Name())
+
+		formFile, err := writer.CreateFormFile(name, filename)
+		if err != nil {
+			return err
+		}
+
+		_, err = io.Copy(formFile, file)
+
+		return err
+	}
+
+	if field.Kind() == reflect.Interface || field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return nil
+		}
+
+		return writeField(writer, name, field.Elem())
+	}
+
+	var fieldValue string
+
+	switch field.Kind() {
+	case reflect.String:
+		fieldValue = field.String()
+	case reflect.Bool:
+		fieldValue = strconv.FormatBool(field.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		fieldValue = strconv.FormatInt(field.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		fieldValue = strconv.FormatUint(field.Uint(), 10)
+	case reflect.Float32:
+		fieldValue = strconv.FormatFloat(field.Float(), 'f', -1, 32)
+	case reflect.Float64:
+		fieldValue = strconv.FormatFloat(field.Float(), 'f', -1, 64)
+	default:
+		return fmt.Errorf("formdata: unsupported type: %s", field.Kind())
+	}
+
+	return writer.WriteField(name, fieldValue)
+}
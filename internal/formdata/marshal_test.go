@@ -114,6 +114,24 @@ func TestMarshal(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "explicit zero pointer",
+			in: struct {
+				Temperature *float64 `form:"temperature,omitempty"`
+				TopP        *float64 `form:"top_p"`
+			}{
+				Temperature: new(float64),
+			},
+			wantErr: false,
+			valid: func(t *testing.T, form *multipart.Form, in any) {
+				if len(form.Value) != 1 {
+					t.Fatalf("expected 1 value, got %d", len(form.Value))
+				}
+				if got, want := form.Value["temperature"][0], "0"; got != want {
+					t.Errorf("expected temperature to be %s, got %s", want, got)
+				}
+			},
+		},
 		{
 			name: "unsupported field type (complex64)",
 			in: struct {
@@ -166,3 +184,41 @@ func TestMarshal(t *testing.T) {
 		})
 	}
 }
+
+func TestMarshalExtra(t *testing.T) {
+	t.Parallel()
+
+	in := struct {
+		Name string `form:"name"`
+	}{
+		Name: "John",
+	}
+
+	data, contentType, err := MarshalExtra(in, map[string]any{"name": "Jane", "age": 20, "nickname": "jd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	form, err := multipart.NewReader(bytes.NewReader(data), params["boundary"]).ReadForm(256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(form.Value) != 3 {
+		t.Fatalf("expected 3 values, got %d", len(form.Value))
+	}
+	if got, want := form.Value["age"][0], "20"; got != want {
+		t.Errorf("expected age to be %s, got %s", want, got)
+	}
+	if got := form.Value["name"]; len(got) != 1 || got[0] != "Jane" {
+		t.Errorf("expected name to be replaced by the extra field, got %v", got)
+	}
+	if got, want := form.Value["nickname"][0], "jd"; got != want {
+		t.Errorf("expected nickname to be %s, got %s", want, got)
+	}
+}
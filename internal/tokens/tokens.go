@@ -0,0 +1,24 @@
+package tokens
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Estimate approximates the number of tokens of the text for OpenAI tokenizers.
+// English text averages about four characters per token; punctuation and
+// non-Latin scripts are denser, so they are counted as a token each.
+func Estimate(text string) int {
+	var latin, other int
+
+	for _, r := range text {
+		switch {
+		case r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsSpace(r)):
+			latin++
+		default:
+			other++
+		}
+	}
+
+	return (latin+3)/4 + other
+}
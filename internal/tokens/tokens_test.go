@@ -0,0 +1,24 @@
+package tokens
+
+import "testing"
+
+func TestEstimate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		text string
+		want int
+	}{
+		{text: "", want: 0},
+		{text: "Hello", want: 2},
+		{text: "This is a test", want: 4},
+		{text: "Hi!", want: 2},
+		{text: "привет", want: 6},
+	}
+
+	for _, tc := range tests {
+		if got := Estimate(tc.text); got != tc.want {
+			t.Errorf("Estimate(%q) = %d, want %d", tc.text, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveHeaders are never logged as is.
+var sensitiveHeaders = []string{"Authorization", "Api-Key", "Openai-Organization"}
+
+// promptFields are the request fields containing prompt content.
+var promptFields = []string{"prompt", "messages", "input", "instruction", "suffix"}
+
+// LogOption configures the logging set up by WithLogger.
+type LogOption func(*logConfig)
+
+type logConfig struct {
+	level         slog.Level
+	bodyLevel     slog.Level
+	redactPrompts bool
+}
+
+// WithLogLevel sets the level of the per-call summary records. Defaults to slog.LevelInfo.
+// Failed calls are always logged at slog.LevelError.
+func WithLogLevel(level slog.Level) LogOption {
+	return func(c *logConfig) {
+		c.level = level
+	}
+}
+
+// WithBodyLogLevel sets the level at which request and response headers and bodies are logged.
+// Defaults to slog.LevelDebug.
+func WithBodyLogLevel(level slog.Level) LogOption {
+	return func(c *logConfig) {
+		c.bodyLevel = level
+	}
+}
+
+// WithPromptRedaction replaces prompt content in logged request bodies and generated content
+// in logged response bodies with a placeholder.
+func WithPromptRedaction() LogOption {
+	return func(c *logConfig) {
+		c.redactPrompts = true
+	}
+}
+
+// WithLogger logs every API call with the given logger.
+// See SlogMiddleware for details.
+func WithLogger(logger *slog.Logger, options ...LogOption) ClientOption {
+	return WithMiddleware(SlogMiddleware(logger, options...))
+}
+
+// SlogMiddleware logs the operation, method, path, model, status, latency, request ID and token usage
+// of every API call to the slog logger. Headers and bodies are logged at a separate level.
+// The API key and organization ID are always redacted.
+func SlogMiddleware(logger *slog.Logger, options ...LogOption) Middleware {
+	config := logConfig{
+		level:     slog.LevelInfo,
+		bodyLevel: slog.LevelDebug,
+	}
+
+	for _, option := range options {
+		option(&config)
+	}
+
+	return func(next Handler) Handler {
+		return func(op Operation, req *http.Request) (*http.Response, error) {
+			ctx := req.Context()
+			logBodies := logger.Enabled(ctx, config.bodyLevel)
+
+			attrs := []slog.Attr{
+				slog.String("operation", op.Name),
+				slog.String("method", req.Method),
+				slog.String("path", req.URL.Path),
+			}
+			if op.Model != "" {
+				attrs = append(attrs, slog.String("model", op.Model))
+			}
+
+			if logBodies {
+				config.logBody(ctx, logger, "openai: request", req.Header, requestBody(req), attrs)
+			}
+
+			start := time.Now()
+
+			resp, err := next(op, req)
+
+			attrs = append(attrs, slog.Duration("latency", time.Since(start)))
+
+			if err != nil {
+				attrs = append(attrs, slog.String("error", err.Error()))
+				logger.LogAttrs(ctx, slog.LevelError, "openai: call failed", attrs...)
+				return resp, err
+			}
+
+			attrs = append(attrs, slog.Int("status", resp.StatusCode))
+
+			if requestID := resp.Header.Get("X-Request-Id"); requestID != "" {
+				attrs = append(attrs, slog.String("request_id", requestID))
+			}
+
+			body, readErr := readResponseBody(resp)
+			if readErr != nil {
+				return nil, readErr
+			}
+
+			if usage, ok := usageOf(body); ok {
+				attrs = append(attrs, slog.Group("usage",
+					slog.Int("prompt_tokens", usage.PromptTokens),
+					slog.Int("completion_tokens", usage.CompletionTokens),
+					slog.Int("total_tokens", usage.TotalTokens),
+				))
+			}
+
+			level := config.level
+			if resp.StatusCode >= http.StatusBadRequest {
+				level = slog.LevelError
+			}
+
+			logger.LogAttrs(ctx, level, "openai: call completed", attrs...)
+
+			if logBodies {
+				config.logBody(ctx, logger, "openai: response", resp.Header, body, attrs)
+			}
+
+			return resp, nil
+		}
+	}
+}
+
+func (c logConfig) logBody(ctx context.Context, logger *slog.Logger, msg string, header http.Header, body []byte, attrs []slog.Attr) {
+	attrs = append(attrs[:len(attrs):len(attrs)],
+		slog.Any("headers", redactHeaders(header)),
+		slog.String("body", c.redactBody(header, body)),
+	)
+
+	logger.LogAttrs(ctx, c.bodyLevel, msg, attrs...)
+}
+
+// redactBody returns the loggable representation of the body.
+// Non-JSON bodies, e.g. multipart uploads, are omitted.
+func (c logConfig) redactBody(header http.Header, body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	if !strings.HasPrefix(header.Get("Content-Type"), "application/json") {
+		return "<omitted " + header.Get("Content-Type") + " body>"
+	}
+
+	if !c.redactPrompts {
+		return string(body)
+	}
+
+	var fields map[string]json.RawMessage
+
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return redacted
+	}
+
+	placeholder, _ := json.Marshal(redacted)
+
+	for _, name := range promptFields {
+		if _, ok := fields[name]; ok {
+			fields[name] = placeholder
+		}
+	}
+
+	if _, ok := fields["choices"]; ok {
+		fields["choices"] = placeholder
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return redacted
+	}
+
+	return string(data)
+}
+
+// redactHeaders returns a copy of the header with the credentials replaced by a placeholder.
+func redactHeaders(header http.Header) http.Header {
+	header = header.Clone()
+
+	for _, key := range sensitiveHeaders {
+		if header.Get(key) != "" {
+			header.Set(key, redacted)
+		}
+	}
+
+	return header
+}
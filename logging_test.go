@@ -0,0 +1,54 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClient_WithLogger(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Id", "req_123")
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"The answer"}}],` +
+			`"usage":{"prompt_tokens":5,"completion_tokens":7,"total_tokens":12}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	buf := new(bytes.Buffer)
+	logger := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	client := NewClient("sk-secret",
+		WithBaseURL(server.URL),
+		WithOrganization("org-secret"),
+		WithLogger(logger, WithPromptRedaction()),
+	)
+
+	_, err := client.ChatCompletion(context.Background(), ChatCompletionRequest{
+		Model:    "gpt-4",
+		Messages: []ChatCompletionRequestMessage{{Role: ChatRoleUser, Content: "private question"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logs := buf.String()
+
+	for _, secret := range []string{"sk-secret", "org-secret", "private question", "The answer"} {
+		if strings.Contains(logs, secret) {
+			t.Errorf("expected %q to be redacted from logs:\n%s", secret, logs)
+		}
+	}
+
+	for _, want := range []string{`"request_id":"req_123"`, `"total_tokens":12`, `"model":"gpt-4"`, `"status":200`} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("expected logs to contain %s:\n%s", want, logs)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package openai
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Price is the price of a model in USD per 1K tokens.
+type Price struct {
+	Prompt     float64
+	Completion float64
+}
+
+// PriceTable maps model names to their prices.
+// A model without an exact entry uses the entry of its longest prefix,
+// so "gpt-4" also prices dated snapshots such as "gpt-4-0613".
+type PriceTable map[string]Price
+
+// Price returns the price of the model and whether the table has one.
+func (t PriceTable) Price(model string) (Price, bool) {
+	if price, ok := t[model]; ok {
+		return price, true
+	}
+
+	var (
+		price  Price
+		prefix string
+	)
+
+	for name, p := range t {
+		if strings.HasPrefix(model, name) && len(name) > len(prefix) {
+			price, prefix = p, name
+		}
+	}
+
+	return price, prefix != ""
+}
+
+// Cost returns the cost of the usage in USD, zero if the model has no price.
+func (t PriceTable) Cost(model string, usage Usage) float64 {
+	price, _ := t.Price(model)
+
+	return (float64(usage.PromptTokens)*price.Prompt + float64(usage.CompletionTokens)*price.Completion) / 1000
+}
+
+// UsageKey identifies a group of metered calls.
+type UsageKey struct {
+	Model     string
+	Operation string
+	User      string
+}
+
+// UsageStats is the accumulated usage of a group of calls.
+type UsageStats struct {
+	Requests         int64
+	PromptTokens     int64
+	CompletionTokens int64
+	TotalTokens      int64
+	Cost             float64
+}
+
+func (s UsageStats) add(other UsageStats) UsageStats {
+	return UsageStats{
+		Requests:         s.Requests + other.Requests,
+		PromptTokens:     s.PromptTokens + other.PromptTokens,
+		CompletionTokens: s.CompletionTokens + other.CompletionTokens,
+		TotalTokens:      s.TotalTokens + other.TotalTokens,
+		Cost:             s.Cost + other.Cost,
+	}
+}
+
+func (s UsageStats) sub(other UsageStats) UsageStats {
+	return UsageStats{
+		Requests:         s.Requests - other.Requests,
+		PromptTokens:     s.PromptTokens - other.PromptTokens,
+		CompletionTokens: s.CompletionTokens - other.CompletionTokens,
+		TotalTokens:      s.TotalTokens - other.TotalTokens,
+		Cost:             s.Cost - other.Cost,
+	}
+}
+
+// UsageSnapshot is a point-in-time copy of the metered usage.
+type UsageSnapshot map[UsageKey]UsageStats
+
+// Sub returns the usage accumulated since the previous snapshot.
+func (s UsageSnapshot) Sub(previous UsageSnapshot) UsageSnapshot {
+	delta := make(UsageSnapshot, len(s))
+
+	for key, stats := range s {
+		if d := stats.sub(previous[key]); d != (UsageStats{}) {
+			delta[key] = d
+		}
+	}
+
+	return delta
+}
+
+// Total returns the usage of all calls.
+func (s UsageSnapshot) Total() UsageStats {
+	var total UsageStats
+
+	for _, stats := range s {
+		total = total.add(stats)
+	}
+
+	return total
+}
+
+// ByModel returns the usage grouped by model.
+func (s UsageSnapshot) ByModel() map[string]UsageStats {
+	return s.groupBy(func(key UsageKey) string { return key.Model })
+}
+
+// ByOperation returns the usage grouped by operation.
+func (s UsageSnapshot) ByOperation() map[string]UsageStats {
+	return s.groupBy(func(key UsageKey) string { return key.Operation })
+}
+
+// ByUser returns the usage grouped by the end-user identifier of the requests.
+func (s UsageSnapshot) ByUser() map[string]UsageStats {
+	return s.groupBy(func(key UsageKey) string { return key.User })
+}
+
+func (s UsageSnapshot) groupBy(group func(UsageKey) string) map[string]UsageStats {
+	groups := make(map[string]UsageStats)
+
+	for key, stats := range s {
+		groups[group(key)] = groups[group(key)].add(stats)
+	}
+
+	return groups
+}
+
+// Meter accumulates the token usage and estimated cost of API calls.
+// It is safe for concurrent use.
+type Meter struct {
+	prices PriceTable
+
+	mu    sync.Mutex
+	stats map[UsageKey]UsageStats
+}
+
+// NewMeter creates a Meter estimating costs with the given price table.
+func NewMeter(prices PriceTable) *Meter {
+	return &Meter{
+		prices: prices,
+		stats:  make(map[UsageKey]UsageStats),
+	}
+}
+
+// Record adds a call with the given usage.
+func (m *Meter) Record(key UsageKey, usage Usage) {
+	stats := UsageStats{
+		Requests:         1,
+		PromptTokens:     int64(usage.PromptTokens),
+		CompletionTokens: int64(usage.CompletionTokens),
+		TotalTokens:      int64(usage.TotalTokens),
+		Cost:             m.prices.Cost(key.Model, usage),
+	}
+
+	m.mu.Lock()
+	m.stats[key] = m.stats[key].add(stats)
+	m.mu.Unlock()
+}
+
+// Snapshot returns a copy of the usage accumulated so far.
+func (m *Meter) Snapshot() UsageSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := make(UsageSnapshot, len(m.stats))
+	for key, stats := range m.stats {
+		snapshot[key] = stats
+	}
+
+	return snapshot
+}
+
+// Handler returns an http.Handler exposing the usage in the Prometheus text exposition format.
+func (m *Meter) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		snapshot := m.Snapshot()
+
+		keys := make([]UsageKey, 0, len(snapshot))
+		for key := range snapshot {
+			keys = append(keys, key)
+		}
+
+		sort.Slice(keys, func(i, j int) bool {
+			a, b := keys[i], keys[j]
+			if a.Model != b.Model {
+				return a.Model < b.Model
+			}
+			if a.Operation != b.Operation {
+				return a.Operation < b.Operation
+			}
+			return a.User < b.User
+		})
+
+		metrics := []struct {
+			name  string
+			help  string
+			value func(UsageStats) string
+		}{
+			{"openai_requests_total", "Total number of successful API calls.", func(s UsageStats) string {
+				return fmt.Sprint(s.Requests)
+			}},
+			{"openai_prompt_tokens_total", "Total number of prompt tokens.", func(s UsageStats) string {
+				return fmt.Sprint(s.PromptTokens)
+			}},
+			{"openai_completion_tokens_total", "Total number of completion tokens.", func(s UsageStats) string {
+				return fmt.Sprint(s.CompletionTokens)
+			}},
+			{"openai_tokens_total", "Total number of tokens.", func(s UsageStats) string {
+				return fmt.Sprint(s.TotalTokens)
+			}},
+			{"openai_cost_usd_total", "Estimated total cost in USD.", func(s UsageStats) string {
+				return fmt.Sprint(s.Cost)
+			}},
+		}
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+		for _, metric := range metrics {
+			fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", metric.name, metric.help, metric.name)
+
+			for _, key := range keys {
+				fmt.Fprintf(w, "%s{model=\"%s\",operation=\"%s\",user=\"%s\"} %s\n", metric.name,
+					escapeLabelValue(key.Model), escapeLabelValue(key.Operation), escapeLabelValue(key.User),
+					metric.value(snapshot[key]))
+			}
+		}
+	})
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(value string) string {
+	return labelValueReplacer.Replace(value)
+}
+
+// WithMeter records the usage of every successful API call in the given meter.
+func WithMeter(meter *Meter) ClientOption {
+	return WithMiddleware(MeterMiddleware(meter))
+}
+
+// MeterMiddleware records the usage of every successful API call in the given meter.
+// Calls are keyed by the requested model, operation and end-user identifier.
+// Responses shared by a Deduplicator with other callers are recorded once.
+func MeterMiddleware(meter *Meter) Middleware {
+	return func(next Handler) Handler {
+		return func(op Operation, req *http.Request) (*http.Response, error) {
+			resp, err := next(op, req)
+			if err != nil || resp.StatusCode != http.StatusOK || sharedResponse(resp) {
+				return resp, err
+			}
+
+			body, err := readResponseBody(resp)
+			if err != nil {
+				return nil, err
+			}
+
+			usage, _ := usageOf(body)
+
+			meter.Record(UsageKey{Model: op.Model, Operation: op.Name, User: op.User}, usage)
+
+			return resp, nil
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package openai
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClient_WithMeter(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"usage":{"prompt_tokens":1000,"completion_tokens":500,"total_tokens":1500}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	meter := NewMeter(PriceTable{
+		"gpt-4":         {Prompt: 0.03, Completion: 0.06},
+		"gpt-3.5-turbo": {Prompt: 0.0015, Completion: 0.002},
+	})
+
+	client := NewClient("test", WithBaseURL(server.URL), WithMeter(meter))
+
+	request := ChatCompletionRequest{Model: "gpt-4-0613", User: "search"}
+
+	if _, err := client.ChatCompletion(context.Background(), request); err != nil {
+		t.Fatal(err)
+	}
+
+	first := meter.Snapshot()
+
+	if _, err := client.ChatCompletion(context.Background(), request); err != nil {
+		t.Fatal(err)
+	}
+
+	total := meter.Snapshot().Total()
+
+	if total.Requests != 2 || total.TotalTokens != 3000 {
+		t.Fatalf("expected 2 requests and 3000 tokens, got %+v", total)
+	}
+	if want := 0.12; math.Abs(total.Cost-want) > 1e-9 {
+		t.Fatalf("expected cost to be %v, got %v", want, total.Cost)
+	}
+
+	delta := meter.Snapshot().Sub(first).ByUser()["search"]
+	if delta.Requests != 1 || delta.PromptTokens != 1000 {
+		t.Fatalf("expected delta of 1 request and 1000 prompt tokens, got %+v", delta)
+	}
+
+	rec := httptest.NewRecorder()
+	meter.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	want := `openai_tokens_total{model="gpt-4-0613",operation="chat.completions",user="search"} 3000`
+	if !strings.Contains(rec.Body.String(), want) {
+		t.Fatalf("expected metrics to contain %s, got:\n%s", want, rec.Body.String())
+	}
+}
@@ -0,0 +1,111 @@
+package openai
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Handler sends an HTTP request belonging to the given operation and returns its response.
+type Handler func(op Operation, req *http.Request) (*http.Response, error)
+
+// Middleware wraps a Handler to run code around API calls,
+// e.g. to log, trace or mutate requests and responses.
+type Middleware func(next Handler) Handler
+
+// chain wraps the handler with the middlewares, so the first middleware is the outermost one.
+func chain(handler Handler, middlewares []Middleware) Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+
+	return handler
+}
+
+// LoggingMiddleware logs the operation, method, path, status and latency of every API call using logf,
+// e.g. log.Printf. Headers and bodies are never logged.
+func LoggingMiddleware(logf func(format string, args ...any)) Middleware {
+	return func(next Handler) Handler {
+		return func(op Operation, req *http.Request) (*http.Response, error) {
+			start := time.Now()
+
+			resp, err := next(op, req)
+			if err != nil {
+				logf("openai: %s %s %s failed after %s: %v", op.Name, req.Method, req.URL.Path, time.Since(start), err)
+				return resp, err
+			}
+
+			logf("openai: %s %s %s %d %s", op.Name, req.Method, req.URL.Path, resp.StatusCode, time.Since(start))
+
+			return resp, nil
+		}
+	}
+}
+
+// HeaderMiddleware sets the given headers on every request, replacing existing values.
+func HeaderMiddleware(header http.Header) Middleware {
+	return MutateRequestMiddleware(func(_ Operation, req *http.Request) {
+		for key, values := range header {
+			req.Header[http.CanonicalHeaderKey(key)] = values
+		}
+	})
+}
+
+// MutateRequestMiddleware calls mutate for every request before it is sent.
+func MutateRequestMiddleware(mutate func(op Operation, req *http.Request)) Middleware {
+	return func(next Handler) Handler {
+		return func(op Operation, req *http.Request) (*http.Response, error) {
+			mutate(op, req)
+			return next(op, req)
+		}
+	}
+}
+
+// requestBody returns a copy of the request body without consuming it, or nil if the body can't be re-read.
+func requestBody(req *http.Request) []byte {
+	if req.GetBody == nil {
+		return nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil
+	}
+
+	return data
+}
+
+// readResponseBody reads the whole response body and replaces it with an in-memory copy,
+// so the caller can inspect the body while the next reader still gets it.
+func readResponseBody(resp *http.Response) ([]byte, error) {
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+
+	return data, nil
+}
+
+// usageOf extracts the token usage from a JSON response body.
+func usageOf(body []byte) (Usage, bool) {
+	var payload struct {
+		Usage *Usage `json:"usage"`
+	}
+
+	if err := json.Unmarshal(body, &payload); err != nil || payload.Usage == nil {
+		return Usage{}, false
+	}
+
+	return *payload.Usage, true
+}
@@ -0,0 +1,50 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestClient_Middleware(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.Header.Get("X-Proxy-Auth"), "secret"; got != want {
+			t.Errorf("expected X-Proxy-Auth header to be %s, got %s", want, got)
+		}
+
+		_, _ = w.Write([]byte(`{"id":"chatcmpl-123"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	var calls []string
+
+	record := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(op Operation, req *http.Request) (*http.Response, error) {
+				calls = append(calls, fmt.Sprintf("%s:%s:%s", name, op.Name, op.Model))
+				return next(op, req)
+			}
+		}
+	}
+
+	client := NewClient("test",
+		WithBaseURL(server.URL),
+		WithMiddleware(record("outer"), HeaderMiddleware(http.Header{"X-Proxy-Auth": {"secret"}})),
+		WithMiddleware(record("inner")),
+	)
+
+	if _, err := client.ChatCompletion(context.Background(), ChatCompletionRequest{Model: "gpt-4"}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"outer:chat.completions:gpt-4", "inner:chat.completions:gpt-4"}
+
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("expected calls to be %v, got %v", want, calls)
+	}
+}
@@ -1,10 +1,11 @@
 package openai
 
 type Model struct {
-	ID      string `json:"id"`
-	Object  string `json:"object"`
-	Created int    `json:"created"`
-	OwnedBy string `json:"owned_by"`
+	ID             string `json:"id"`
+	Object         string `json:"object"`
+	Created        int    `json:"created"`
+	OwnedBy        string `json:"owned_by"`
+	ResponseExtras `json:"-"`
 }
 
 type ChatRole string
@@ -15,10 +16,14 @@ const (
 	ChatRoleAssistant ChatRole = "assistant"
 )
 
+// ChatCompletionRequestMessage is a message of a chat conversation.
+// Content holds a plain text message, MultiContent holds a message made of typed parts (text, images, audio, files).
+// Only one of them should be set; MultiContent takes precedence when it is not empty.
 type ChatCompletionRequestMessage struct {
-	Role    ChatRole `json:"role"`
-	Content string   `json:"content"`
-	Name    string   `json:"name,omitempty"`
+	Role         ChatRole
+	Content      string
+	MultiContent []ChatContentPart
+	Name         string
 }
 
 type ChatCompletionResponseMessage struct {
@@ -26,15 +31,79 @@ type ChatCompletionResponseMessage struct {
 	Content string   `json:"content"`
 }
 
+type ChatCompletionChoice struct {
+	Index        int                           `json:"index"`
+	Message      ChatCompletionResponseMessage `json:"message"`
+	FinishReason string                        `json:"finish_reason"`
+}
+
+type CompletionChoice struct {
+	Text         string   `json:"text"`
+	Index        int      `json:"index"`
+	Logprobs     Logprobs `json:"logprobs"`
+	FinishReason string   `json:"finish_reason"`
+}
+
+type Logprobs struct {
+	Tokens        []string             `json:"tokens"`
+	TokenLogprobs []float64            `json:"token_logprobs"`
+	TopLogprobs   []map[string]float64 `json:"top_logprobs"`
+	TextOffset    []int                `json:"text_offset"`
+}
+
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+type ImageData struct {
+	URL     string `json:"url"`
+	B64JSON string `json:"b64_json"`
+}
+
+type EmbeddingData struct {
+	Index     int       `json:"index"`
+	Object    string    `json:"object"`
+	Embedding []float32 `json:"embedding"`
+}
+
+type ModerationResult struct {
+	Flagged        bool                     `json:"flagged"`
+	Categories     ModerationCategories     `json:"categories"`
+	CategoryScores ModerationCategoryScores `json:"category_scores"`
+}
+
+type ModerationCategories struct {
+	Hate            bool `json:"hate"`
+	HateThreatening bool `json:"hate/threatening"`
+	SelfHarm        bool `json:"self-harm"`
+	Sexual          bool `json:"sexual"`
+	SexualMinors    bool `json:"sexual/minors"`
+	Violence        bool `json:"violence"`
+	ViolenceGraphic bool `json:"violence/graphic"`
+}
+
+type ModerationCategoryScores struct {
+	Hate            float64 `json:"hate"`
+	HateThreatening float64 `json:"hate/threatening"`
+	SelfHarm        float64 `json:"self-harm"`
+	Sexual          float64 `json:"sexual"`
+	SexualMinors    float64 `json:"sexual/minors"`
+	Violence        float64 `json:"violence"`
+	ViolenceGraphic float64 `json:"violence/graphic"`
+}
+
 type File struct {
-	ID            string         `json:"id"`
-	Object        string         `json:"object"`
-	Bytes         int            `json:"bytes"`
-	CreatedAt     int            `json:"created_at"`
-	Filename      string         `json:"filename"`
-	Purpose       string         `json:"purpose"`
-	Status        string         `json:"status"`
-	StatusDetails map[string]any `json:"status_details"`
+	ID             string         `json:"id"`
+	Object         string         `json:"object"`
+	Bytes          int            `json:"bytes"`
+	CreatedAt      int            `json:"created_at"`
+	Filename       string         `json:"filename"`
+	Purpose        string         `json:"purpose"`
+	Status         string         `json:"status"`
+	StatusDetails  map[string]any `json:"status_details"`
+	ResponseExtras `json:"-"`
 }
 
 type FineTune struct {
@@ -51,6 +120,7 @@ type FineTune struct {
 	ValidationFiles []File          `json:"validation_files"`
 	ResultFiles     []File          `json:"result_files"`
 	Events          []FineTuneEvent `json:"events"`
+	ResponseExtras  `json:"-"`
 }
 
 type FineTuneEvent struct {
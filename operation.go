@@ -0,0 +1,58 @@
+package openai
+
+import "reflect"
+
+// Logical names of the API operations, passed to middlewares as Operation.Name.
+const (
+	OperationModelsList          = "models.list"
+	OperationModelsRetrieve      = "models.retrieve"
+	OperationModelsDelete        = "models.delete"
+	OperationCompletions         = "completions"
+	OperationChatCompletions     = "chat.completions"
+	OperationEdits               = "edits"
+	OperationImagesGenerate      = "images.generate"
+	OperationImagesEdit          = "images.edit"
+	OperationImagesVariation     = "images.variation"
+	OperationEmbeddings          = "embeddings"
+	OperationAudioTranscriptions = "audio.transcriptions"
+	OperationAudioTranslations   = "audio.translations"
+	OperationFilesList           = "files.list"
+	OperationFilesUpload         = "files.upload"
+	OperationFilesDelete         = "files.delete"
+	OperationFilesRetrieve       = "files.retrieve"
+	OperationFilesContent        = "files.content"
+	OperationFineTunesCreate     = "fine_tunes.create"
+	OperationFineTunesList       = "fine_tunes.list"
+	OperationFineTunesRetrieve   = "fine_tunes.retrieve"
+	OperationFineTunesCancel     = "fine_tunes.cancel"
+	OperationFineTunesEvents     = "fine_tunes.events"
+	OperationModerations         = "moderations"
+)
+
+// Operation describes the logical API call an HTTP request belongs to.
+type Operation struct {
+	// Name is the logical operation name, e.g. "chat.completions".
+	Name string
+	// Model is the model the request is made for, empty if the request has no model.
+	Model string
+	// User is the end-user identifier sent with the request, if any.
+	User string
+	// Payload is the request value passed to the Client method, nil for requests without a body.
+	Payload any
+}
+
+func newOperation(name string, payload any) Operation {
+	op := Operation{Name: name, Payload: payload}
+
+	if v := reflect.Indirect(reflect.ValueOf(payload)); v.Kind() == reflect.Struct {
+		if model := v.FieldByName("Model"); model.Kind() == reflect.String {
+			op.Model = model.String()
+		}
+
+		if user := v.FieldByName("User"); user.Kind() == reflect.String {
+			op.User = user.String()
+		}
+	}
+
+	return op
+}
@@ -0,0 +1,12 @@
+package openai
+
+// Float returns a pointer to the given value.
+// Optional request parameters are pointers, so zero values such as a temperature of 0 are sent when explicitly set.
+func Float(v float64) *float64 {
+	return &v
+}
+
+// Int returns a pointer to the given value.
+func Int(v int) *int {
+	return &v
+}
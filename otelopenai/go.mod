@@ -0,0 +1,11 @@
+module github.com/KirillMironov/openai/otelopenai
+
+go 1.21
+
+require (
+	github.com/KirillMironov/openai v0.0.0
+	go.opentelemetry.io/otel v1.24.0
+	go.opentelemetry.io/otel/trace v1.24.0
+)
+
+replace github.com/KirillMironov/openai => ../
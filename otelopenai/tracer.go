@@ -0,0 +1,86 @@
+// Package otelopenai adapts OpenTelemetry tracing to the openai client.
+//
+//	client := openai.NewClient(apiKey, openai.WithTracer(otelopenai.NewTracer(otel.Tracer("openai"))))
+package otelopenai
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/KirillMironov/openai"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type tracer struct {
+	tracer trace.Tracer
+}
+
+// NewTracer returns an openai.Tracer starting client spans with the given OpenTelemetry tracer.
+func NewTracer(t trace.Tracer) openai.Tracer {
+	return tracer{tracer: t}
+}
+
+func (t tracer) Start(ctx context.Context, name string, attrs ...openai.Attribute) (context.Context, openai.Span) {
+	ctx, s := t.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(keyValues(attrs)...),
+	)
+
+	return ctx, span{span: s}
+}
+
+type span struct {
+	span trace.Span
+}
+
+func (s span) SetAttributes(attrs ...openai.Attribute) {
+	s.span.SetAttributes(keyValues(attrs)...)
+}
+
+func (s span) RecordError(err error) {
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+func (s span) End() {
+	s.span.End()
+}
+
+func keyValues(attrs []openai.Attribute) []attribute.KeyValue {
+	keyValues := make([]attribute.KeyValue, 0, len(attrs))
+
+	for _, attr := range attrs {
+		keyValues = append(keyValues, keyValue(attr))
+	}
+
+	return keyValues
+}
+
+func keyValue(attr openai.Attribute) attribute.KeyValue {
+	key := attribute.Key(attr.Key)
+
+	switch value := attr.Value.(type) {
+	case string:
+		return key.String(value)
+	case bool:
+		return key.Bool(value)
+	case int:
+		return key.Int(value)
+	case int64:
+		return key.Int64(value)
+	case float64:
+		return key.Float64(value)
+	case []string:
+		return key.StringSlice(value)
+	case []bool:
+		return key.BoolSlice(value)
+	case []int:
+		return key.IntSlice(value)
+	case []float64:
+		return key.Float64Slice(value)
+	default:
+		return key.String(fmt.Sprint(value))
+	}
+}
@@ -0,0 +1,255 @@
+// Package rag answers questions about documents with retrieval-augmented generation:
+// documents are split and embedded into a vector index, and the passages most similar to a question
+// are added to the chat prompt answering it.
+package rag
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/KirillMironov/openai"
+	"github.com/KirillMironov/openai/internal/tokens"
+	"github.com/KirillMironov/openai/textsplit"
+	"github.com/KirillMironov/openai/vector"
+)
+
+// Metadata keys of the passages stored in the index, alongside the document metadata.
+const (
+	MetadataDocumentID = "rag.document_id"
+	MetadataText       = "rag.text"
+	MetadataStart      = "rag.start"
+	MetadataEnd        = "rag.end"
+)
+
+const defaultSystemPrompt = `Answer the question using only the numbered sources below.
+Cite the sources supporting each statement with their number in brackets, e.g. [1].
+If the sources do not contain the answer, say that you don't know.`
+
+// Config configures a Pipeline. Zero values are replaced by the defaults.
+type Config struct {
+	// ChatModel answers the questions. Required.
+	ChatModel string
+	// EmbeddingModel embeds the documents and questions. Required.
+	EmbeddingModel string
+	// Splitter cuts documents into passages. Defaults to passages of 400 tokens overlapping by 40.
+	Splitter textsplit.Splitter
+	// Index stores the passages. Defaults to an exact-search index.
+	Index *vector.Index
+	// TopK is the number of passages retrieved per question. Defaults to 5.
+	TopK int
+	// MaxContextTokens caps the estimated tokens of the passages added to the prompt. Defaults to 3000.
+	// Passages are added best first, the ones exceeding the budget are skipped.
+	MaxContextTokens int
+	// SystemPrompt instructs the model how to answer. Defaults to answering from the sources with citations.
+	SystemPrompt string
+	// RequestOptions are applied to every chat completion.
+	RequestOptions []openai.RequestOption
+}
+
+// Document is a text to answer questions about.
+type Document struct {
+	ID       string
+	Text     string
+	Metadata map[string]string
+}
+
+// Source is a passage added to the prompt.
+type Source struct {
+	// Marker is the number citing the passage in the answer, e.g. 1 for [1].
+	Marker     int
+	DocumentID string
+	// Chunk is the passage with its offsets in the document.
+	Chunk    textsplit.Chunk
+	Score    float32
+	Metadata map[string]string
+}
+
+// Answer is the answer to a question.
+type Answer struct {
+	Text     string
+	Sources  []Source
+	Response openai.ChatCompletionResponse
+}
+
+// Pipeline indexes documents and answers questions about them.
+type Pipeline struct {
+	client   *openai.Client
+	embedder *openai.Embedder
+	config   Config
+}
+
+// New creates a Pipeline.
+func New(client *openai.Client, config Config) *Pipeline {
+	if config.Splitter == nil {
+		config.Splitter = textsplit.NewTokenSplitter(400, 40)
+	}
+	if config.Index == nil {
+		config.Index = vector.NewIndex()
+	}
+	if config.TopK <= 0 {
+		config.TopK = 5
+	}
+	if config.MaxContextTokens <= 0 {
+		config.MaxContextTokens = 3000
+	}
+	if config.SystemPrompt == "" {
+		config.SystemPrompt = defaultSystemPrompt
+	}
+
+	return &Pipeline{
+		client:   client,
+		embedder: openai.NewEmbedder(client, config.EmbeddingModel, openai.EmbedAllOptions{}),
+		config:   config,
+	}
+}
+
+// Index returns the index of the passages, e.g. to save it.
+func (p *Pipeline) Index() *vector.Index {
+	return p.config.Index
+}
+
+// AddDocuments splits, embeds and indexes the documents.
+// Passages of a document added again replace the previous ones, extra ones are deleted.
+func (p *Pipeline) AddDocuments(ctx context.Context, documents ...Document) error {
+	var (
+		records []vector.Record
+		texts   []string
+		counts  = make([]int, len(documents))
+	)
+
+	for i, document := range documents {
+		chunks := p.config.Splitter.Split(document.Text)
+		counts[i] = len(chunks)
+
+		for n, chunk := range chunks {
+			metadata := make(map[string]string, len(document.Metadata)+4)
+			for key, value := range document.Metadata {
+				metadata[key] = value
+			}
+
+			metadata[MetadataDocumentID] = document.ID
+			metadata[MetadataText] = chunk.Text
+			metadata[MetadataStart] = strconv.Itoa(chunk.Start)
+			metadata[MetadataEnd] = strconv.Itoa(chunk.End)
+
+			records = append(records, vector.Record{ID: passageID(document.ID, n), Metadata: metadata})
+			texts = append(texts, chunk.Text)
+		}
+	}
+
+	vectors, err := p.embedder.Embed(ctx, texts)
+	if err != nil {
+		return err
+	}
+
+	for i := range records {
+		records[i].Vector = vectors[i]
+	}
+
+	if err = p.config.Index.Add(records...); err != nil {
+		return err
+	}
+
+	for i, document := range documents {
+		p.deletePassages(document.ID, counts[i])
+	}
+
+	return nil
+}
+
+// deletePassages deletes the passages of the document from the n-th one on.
+func (p *Pipeline) deletePassages(documentID string, n int) {
+	for ; ; n++ {
+		id := passageID(documentID, n)
+		if _, ok := p.config.Index.Get(id); !ok {
+			return
+		}
+
+		p.config.Index.Delete(id)
+	}
+}
+
+func passageID(documentID string, n int) string {
+	return documentID + "#" + strconv.Itoa(n)
+}
+
+// Retrieve returns the passages most similar to the question within the token budget, numbered from 1.
+func (p *Pipeline) Retrieve(ctx context.Context, question string, options ...vector.SearchOption) ([]Source, error) {
+	vectors, err := p.embedder.Embed(ctx, []string{question})
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := p.config.Index.Search(vectors[0], p.config.TopK, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		sources []Source
+		budget  = p.config.MaxContextTokens
+	)
+
+	for _, result := range results {
+		text := result.Metadata[MetadataText]
+
+		passageTokens := tokens.Estimate(text)
+		if passageTokens > budget {
+			continue
+		}
+		budget -= passageTokens
+
+		start, _ := strconv.Atoi(result.Metadata[MetadataStart])
+		end, _ := strconv.Atoi(result.Metadata[MetadataEnd])
+
+		sources = append(sources, Source{
+			Marker:     len(sources) + 1,
+			DocumentID: result.Metadata[MetadataDocumentID],
+			Chunk:      textsplit.Chunk{Text: text, Start: start, End: end},
+			Score:      result.Score,
+			Metadata:   result.Metadata,
+		})
+	}
+
+	return sources, nil
+}
+
+// Ask answers the question with the retrieved passages.
+// Search options, e.g. vector.WithFilter, restrict the passages which may be retrieved.
+func (p *Pipeline) Ask(ctx context.Context, question string, options ...vector.SearchOption) (Answer, error) {
+	sources, err := p.Retrieve(ctx, question, options...)
+	if err != nil {
+		return Answer{}, err
+	}
+
+	resp, err := p.client.ChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model: p.config.ChatModel,
+		Messages: []openai.ChatCompletionRequestMessage{
+			{Role: openai.ChatRoleSystem, Content: p.config.SystemPrompt},
+			{Role: openai.ChatRoleUser, Content: Prompt(question, sources)},
+		},
+	}, p.config.RequestOptions...)
+	if err != nil {
+		return Answer{}, err
+	}
+
+	return Answer{Text: resp.FirstText(), Sources: sources, Response: resp}, nil
+}
+
+// Prompt returns the user message asking the question with the numbered sources.
+func Prompt(question string, sources []Source) string {
+	var sb strings.Builder
+
+	sb.WriteString("Sources:\n")
+
+	for _, source := range sources {
+		fmt.Fprintf(&sb, "\n[%d] %s\n", source.Marker, source.Chunk.Text)
+	}
+
+	sb.WriteString("\nQuestion: ")
+	sb.WriteString(question)
+
+	return sb.String()
+}
@@ -0,0 +1,118 @@
+package rag
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/KirillMironov/openai"
+	"github.com/KirillMironov/openai/textsplit"
+	"github.com/KirillMironov/openai/vector"
+)
+
+// topics are the dimensions of the test embeddings, which count the occurrences of each topic.
+var topics = []string{"cat", "dog", "fish"}
+
+func TestPipeline_Ask(t *testing.T) {
+	t.Parallel()
+
+	var prompt string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/embeddings":
+			var request openai.EmbeddingRequest
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				t.Error(err)
+				return
+			}
+
+			var resp openai.EmbeddingResponse
+			for i, input := range request.Input {
+				embedding := make([]float32, len(topics))
+				for d, topic := range topics {
+					embedding[d] = float32(strings.Count(input, topic))
+				}
+				resp.Data = append(resp.Data, openai.EmbeddingData{Index: i, Embedding: embedding})
+			}
+
+			_ = json.NewEncoder(w).Encode(resp)
+		case "/chat/completions":
+			var request openai.ChatCompletionRequest
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				t.Error(err)
+				return
+			}
+
+			prompt = request.Messages[1].Content
+
+			_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"Cats purr [1]."}}]}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	pipeline := New(openai.NewClient("test", openai.WithBaseURL(server.URL)), Config{
+		ChatModel:      "gpt-4",
+		EmbeddingModel: "ada",
+		Splitter:       textsplit.NewTokenSplitter(7, 0),
+		TopK:           2,
+	})
+
+	err := pipeline.AddDocuments(context.Background(),
+		Document{ID: "pets", Text: "the cat purrs cat cat. the dog barks dog dog.", Metadata: map[string]string{"lang": "en"}},
+		Document{ID: "sea", Text: "the fish swims fish fish."},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	answer, err := pipeline.Ask(context.Background(), "what does the cat do?", vector.WithFilter(vector.Equals("lang", "en")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if answer.Text != "Cats purr [1]." {
+		t.Fatalf("expected the answer of the model, got %q", answer.Text)
+	}
+
+	if len(answer.Sources) != 2 {
+		t.Fatalf("expected 2 sources, got %+v", answer.Sources)
+	}
+
+	first := answer.Sources[0]
+	if first.Marker != 1 || first.DocumentID != "pets" || first.Chunk.Text != "the cat purrs cat cat." || first.Chunk.Start != 0 {
+		t.Fatalf("expected the cat passage first, got %+v", first)
+	}
+
+	if !strings.Contains(prompt, "[1] the cat purrs cat cat.") || !strings.HasSuffix(prompt, "Question: what does the cat do?") {
+		t.Fatalf("expected the prompt to contain the numbered sources and the question, got:\n%s", prompt)
+	}
+
+	if strings.Contains(prompt, "fish") {
+		t.Fatalf("expected the filtered out document to be excluded, got:\n%s", prompt)
+	}
+
+	// Passages exceeding the remaining budget are skipped.
+	pipeline.config.MaxContextTokens = 8
+
+	sources, err := pipeline.Retrieve(context.Background(), "cat")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sources) != 1 {
+		t.Fatalf("expected 1 source within the budget, got %+v", sources)
+	}
+
+	// Re-adding a shorter document deletes its extra passages.
+	if err = pipeline.AddDocuments(context.Background(), Document{ID: "pets", Text: "the cat."}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := pipeline.Index().Get("pets#1"); ok {
+		t.Fatal("expected the extra passage to be deleted")
+	}
+}
@@ -7,42 +7,45 @@ type CompletionRequest struct {
 	Prompt           []string       `json:"prompt,omitempty"`
 	Suffix           string         `json:"suffix,omitempty"`
 	MaxTokens        int            `json:"max_tokens,omitempty"`
-	Temperature      float64        `json:"temperature,omitempty"`
-	TopP             float64        `json:"top_p,omitempty"`
-	N                int            `json:"n,omitempty"`
+	Temperature      *float64       `json:"temperature,omitempty"`
+	TopP             *float64       `json:"top_p,omitempty"`
+	N                *int           `json:"n,omitempty"`
 	Stream           bool           `json:"stream,omitempty"`
-	Logprobs         int            `json:"logprobs,omitempty"`
+	Logprobs         *int           `json:"logprobs,omitempty"`
 	Echo             bool           `json:"echo,omitempty"`
 	Stop             []string       `json:"stop,omitempty"`
-	PresencePenalty  float64        `json:"presence_penalty,omitempty"`
-	FrequencyPenalty float64        `json:"frequency_penalty,omitempty"`
+	PresencePenalty  *float64       `json:"presence_penalty,omitempty"`
+	FrequencyPenalty *float64       `json:"frequency_penalty,omitempty"`
 	BestOf           int            `json:"best_of,omitempty"`
 	LogitBias        map[string]int `json:"logit_bias,omitempty"`
 	User             string         `json:"user,omitempty"`
+	RequestExtras    `json:"-" form:"-"`
 }
 
 type ChatCompletionRequest struct {
 	Model            string                         `json:"model"`
 	Messages         []ChatCompletionRequestMessage `json:"messages"`
-	Temperature      float64                        `json:"temperature,omitempty"`
-	TopP             float64                        `json:"top_p,omitempty"`
-	N                int                            `json:"n,omitempty"`
+	Temperature      *float64                       `json:"temperature,omitempty"`
+	TopP             *float64                       `json:"top_p,omitempty"`
+	N                *int                           `json:"n,omitempty"`
 	Stream           bool                           `json:"stream,omitempty"`
 	Stop             []string                       `json:"stop,omitempty"`
 	MaxTokens        int                            `json:"max_tokens,omitempty"`
-	PresencePenalty  float64                        `json:"presence_penalty,omitempty"`
-	FrequencyPenalty float64                        `json:"frequency_penalty,omitempty"`
+	PresencePenalty  *float64                       `json:"presence_penalty,omitempty"`
+	FrequencyPenalty *float64                       `json:"frequency_penalty,omitempty"`
 	LogitBias        map[string]int                 `json:"logit_bias,omitempty"`
 	User             string                         `json:"user,omitempty"`
+	RequestExtras    `json:"-" form:"-"`
 }
 
 type EditRequest struct {
-	Model       string  `json:"model"`
-	Input       string  `json:"input,omitempty"`
-	Instruction string  `json:"instruction"`
-	N           int     `json:"n,omitempty"`
-	Temperature float64 `json:"temperature,omitempty"`
-	TopP        float64 `json:"top_p,omitempty"`
+	Model         string   `json:"model"`
+	Input         string   `json:"input,omitempty"`
+	Instruction   string   `json:"instruction"`
+	N             *int     `json:"n,omitempty"`
+	Temperature   *float64 `json:"temperature,omitempty"`
+	TopP          *float64 `json:"top_p,omitempty"`
+	RequestExtras `json:"-" form:"-"`
 }
 
 type ImageSize string
@@ -66,6 +69,7 @@ type ImageRequest struct {
 	Size           ImageSize           `json:"size,omitempty"`
 	ResponseFormat ImageResponseFormat `json:"response_format,omitempty"`
 	User           string              `json:"user,omitempty"`
+	RequestExtras  `json:"-" form:"-"`
 }
 
 type ImageEditRequest struct {
@@ -76,6 +80,7 @@ type ImageEditRequest struct {
 	Size           ImageSize           `form:"size,omitempty"`
 	ResponseFormat ImageResponseFormat `form:"response_format,omitempty"`
 	User           string              `form:"user,omitempty"`
+	RequestExtras  `json:"-" form:"-"`
 }
 
 type ImageVariationRequest struct {
@@ -84,12 +89,26 @@ type ImageVariationRequest struct {
 	Size           ImageSize           `form:"size,omitempty"`
 	ResponseFormat ImageResponseFormat `form:"response_format,omitempty"`
 	User           string              `form:"user,omitempty"`
+	RequestExtras  `json:"-" form:"-"`
 }
 
+type EmbeddingEncodingFormat string
+
+const (
+	EmbeddingEncodingFormatFloat EmbeddingEncodingFormat = "float"
+	// EmbeddingEncodingFormatBase64 makes the API send embeddings as base64-encoded little-endian float32 values,
+	// which are about four times smaller than JSON numbers. They are decoded transparently.
+	EmbeddingEncodingFormatBase64 EmbeddingEncodingFormat = "base64"
+)
+
 type EmbeddingRequest struct {
-	Model string   `json:"model"`
-	Input []string `json:"input"`
-	User  string   `json:"user,omitempty"`
+	Model          string                  `json:"model"`
+	Input          []string                `json:"input"`
+	EncodingFormat EmbeddingEncodingFormat `json:"encoding_format,omitempty"`
+	// Dimensions shortens the embeddings of the models supporting it.
+	Dimensions    int    `json:"dimensions,omitempty"`
+	User          string `json:"user,omitempty"`
+	RequestExtras `json:"-" form:"-"`
 }
 
 type TranscriptionResponseFormat string
@@ -104,11 +123,12 @@ const (
 
 type TranscriptionRequest struct {
 	File           formdata.File               `form:"file"`
-	Model          string                      `json:"model"`
-	Prompt         string                      `json:"prompt,omitempty"`
-	ResponseFormat TranscriptionResponseFormat `json:"response_format,omitempty"`
-	Temperature    float64                     `json:"temperature,omitempty"`
-	Language       string                      `json:"language,omitempty"`
+	Model          string                      `form:"model"`
+	Prompt         string                      `form:"prompt,omitempty"`
+	ResponseFormat TranscriptionResponseFormat `form:"response_format,omitempty"`
+	Temperature    *float64                    `form:"temperature,omitempty"`
+	Language       string                      `form:"language,omitempty"`
+	RequestExtras  `json:"-" form:"-"`
 }
 
 type TranslationResponseFormat string
@@ -123,15 +143,17 @@ const (
 
 type TranslationRequest struct {
 	File           formdata.File             `form:"file"`
-	Model          string                    `json:"model"`
-	Prompt         string                    `json:"prompt,omitempty"`
-	ResponseFormat TranslationResponseFormat `json:"response_format,omitempty"`
-	Temperature    float64                   `json:"temperature,omitempty"`
+	Model          string                    `form:"model"`
+	Prompt         string                    `form:"prompt,omitempty"`
+	ResponseFormat TranslationResponseFormat `form:"response_format,omitempty"`
+	Temperature    *float64                  `form:"temperature,omitempty"`
+	RequestExtras  `json:"-" form:"-"`
 }
 
 type UploadFileRequest struct {
-	File    formdata.File `form:"file"`
-	Purpose string        `form:"purpose"`
+	File          formdata.File `form:"file"`
+	Purpose       string        `form:"purpose"`
+	RequestExtras `json:"-" form:"-"`
 }
 
 type FineTuneRequest struct {
@@ -147,9 +169,11 @@ type FineTuneRequest struct {
 	ClassificationPositiveClass  string    `json:"classification_positive_class,omitempty"`
 	ClassificationBetas          []float64 `json:"classification_betas,omitempty"`
 	Suffix                       string    `json:"suffix,omitempty"`
+	RequestExtras                `json:"-" form:"-"`
 }
 
 type ModerationRequest struct {
-	Input []string `json:"input"`
-	Model string   `json:"model,omitempty"`
+	Input         []string `json:"input"`
+	Model         string   `json:"model,omitempty"`
+	RequestExtras `json:"-" form:"-"`
 }
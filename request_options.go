@@ -0,0 +1,91 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// RequestOption configures a single API call, overriding the Client defaults.
+type RequestOption func(*requestOptions)
+
+type requestOptions struct {
+	header     http.Header
+	query      url.Values
+	timeout    time.Duration
+	baseURL    string
+	maxRetries int
+	noCache    bool
+}
+
+func newRequestOptions(client *Client, options []RequestOption) requestOptions {
+	opts := requestOptions{
+		header:  make(http.Header),
+		query:   make(url.Values),
+		baseURL: client.baseURL,
+	}
+
+	for _, option := range options {
+		option(&opts)
+	}
+
+	return opts
+}
+
+func (o requestOptions) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	if o.timeout > 0 {
+		return context.WithTimeout(ctx, o.timeout)
+	}
+
+	return context.WithCancel(ctx)
+}
+
+// WithHeader sets a header on the request, replacing any value set by the Client.
+func WithHeader(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		o.header.Set(key, value)
+	}
+}
+
+// WithQuery sets a query parameter on the request, replacing any value set by RequestExtras.
+func WithQuery(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		o.query.Set(key, value)
+	}
+}
+
+// WithRequestTimeout limits the duration of the call, including retries and fallback models.
+func WithRequestTimeout(timeout time.Duration) RequestOption {
+	return func(o *requestOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithIdempotencyKey sets the Idempotency-Key header, so the server can safely deduplicate retried requests.
+func WithIdempotencyKey(key string) RequestOption {
+	return WithHeader("Idempotency-Key", key)
+}
+
+// WithRequestBaseURL sends the request to the given base URL instead of the Client one.
+func WithRequestBaseURL(baseURL string) RequestOption {
+	return func(o *requestOptions) {
+		o.baseURL = baseURL
+	}
+}
+
+// WithMaxRetries retries the request up to maxRetries times on network errors,
+// rate limiting (429) and server errors (5xx), with exponential backoff.
+func WithMaxRetries(maxRetries int) RequestOption {
+	return func(o *requestOptions) {
+		o.maxRetries = maxRetries
+	}
+}
+
+// WithCacheBypass sends the request to the API even if its response is cached by WithResponseCache or WithSemanticCache.
+// The new response still replaces the cached one.
+func WithCacheBypass() RequestOption {
+	return func(o *requestOptions) {
+		o.noCache = true
+	}
+}
@@ -0,0 +1,73 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_RequestOptions(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.Header.Get("Idempotency-Key"), "key-1"; got != want {
+			t.Errorf("expected Idempotency-Key header to be %s, got %s", want, got)
+		}
+		if got, want := r.Header.Get("X-Trace"), "abc"; got != want {
+			t.Errorf("expected X-Trace header to be %s, got %s", want, got)
+		}
+
+		if attempts.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"object":"list","data":[{"object":"embedding","index":0,"embedding":[0.1]}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient("test", WithBaseURL("http://127.0.0.1:0"))
+
+	resp, err := client.Embedding(context.Background(), EmbeddingRequest{Model: "text-embedding-ada-002"},
+		WithRequestBaseURL(server.URL),
+		WithIdempotencyKey("key-1"),
+		WithHeader("X-Trace", "abc"),
+		WithMaxRetries(1),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := attempts.Load(); got != 2 {
+		t.Fatalf("expected 2 attempts, got %d", got)
+	}
+	if len(resp.Data) != 1 {
+		t.Fatalf("expected 1 embedding, got %d", len(resp.Data))
+	}
+}
+
+func TestClient_RequestTimeout(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient("test", WithBaseURL(server.URL))
+
+	_, err := client.Models(context.Background(), WithRequestTimeout(10*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded error, got %v", err)
+	}
+}
@@ -1,168 +1,162 @@
 package openai
 
 type ModelsResponse struct {
-	Object string  `json:"object"`
-	Data   []Model `json:"data"`
+	Object         string  `json:"object"`
+	Data           []Model `json:"data"`
+	ResponseExtras `json:"-"`
 }
 
 type DeleteModelResponse struct {
-	ID      string `json:"id"`
-	Object  string `json:"object"`
-	Deleted bool   `json:"deleted"`
+	ID             string `json:"id"`
+	Object         string `json:"object"`
+	Deleted        bool   `json:"deleted"`
+	ResponseExtras `json:"-"`
 }
 
 type ChatCompletionResponse struct {
-	ID      string `json:"id"`
-	Object  string `json:"object"`
-	Created int    `json:"created"`
-	Model   string `json:"model"`
-	Choices []struct {
-		Index        int                           `json:"index"`
-		Message      ChatCompletionResponseMessage `json:"message"`
-		FinishReason string                        `json:"finish_reason"`
-	} `json:"choices"`
-	Usage struct {
-		PromptTokens     int `json:"prompt_tokens"`
-		CompletionTokens int `json:"completion_tokens"`
-		TotalTokens      int `json:"total_tokens"`
-	} `json:"usage"`
+	ID      string                 `json:"id"`
+	Object  string                 `json:"object"`
+	Created int                    `json:"created"`
+	Model   string                 `json:"model"`
+	Choices []ChatCompletionChoice `json:"choices"`
+	Usage   Usage                  `json:"usage"`
+	// AnsweredBy is the requested model which produced the response, see WithFallback.
+	AnsweredBy string `json:"-"`
+	// Cached reports whether the response was served from a cache, see WithResponseCache and WithSemanticCache.
+	Cached         bool `json:"-"`
+	ResponseExtras `json:"-"`
 }
 
 type CompletionResponse struct {
-	ID      string `json:"id"`
-	Object  string `json:"object"`
-	Created int    `json:"created"`
-	Model   string `json:"model"`
-	Choices []struct {
-		Text     string `json:"text"`
-		Index    int    `json:"index"`
-		Logprobs struct {
-			Tokens        []string             `json:"tokens"`
-			TokenLogprobs []float64            `json:"token_logprobs"`
-			TopLogprobs   []map[string]float64 `json:"top_logprobs"`
-			TextOffset    []int                `json:"text_offset"`
-		} `json:"logprobs"`
-		FinishReason string `json:"finish_reason"`
-	} `json:"choices"`
-	Usage struct {
-		PromptTokens     int `json:"prompt_tokens"`
-		CompletionTokens int `json:"completion_tokens"`
-		TotalTokens      int `json:"total_tokens"`
-	} `json:"usage"`
+	ID      string             `json:"id"`
+	Object  string             `json:"object"`
+	Created int                `json:"created"`
+	Model   string             `json:"model"`
+	Choices []CompletionChoice `json:"choices"`
+	Usage   Usage              `json:"usage"`
+	// AnsweredBy is the requested model which produced the response, see WithFallback.
+	AnsweredBy string `json:"-"`
+	// Cached reports whether the response was served from a cache, see WithResponseCache.
+	Cached         bool `json:"-"`
+	ResponseExtras `json:"-"`
 }
 
 type EditResponse struct {
-	ID      string `json:"id"`
-	Object  string `json:"object"`
-	Created int    `json:"created"`
-	Model   string `json:"model"`
-	Choices []struct {
-		Text     string `json:"text"`
-		Index    int    `json:"index"`
-		Logprobs struct {
-			Tokens        []string             `json:"tokens"`
-			TokenLogprobs []float64            `json:"token_logprobs"`
-			TopLogprobs   []map[string]float64 `json:"top_logprobs"`
-			TextOffset    []int                `json:"text_offset"`
-		} `json:"logprobs"`
-		FinishReason string `json:"finish_reason"`
-	} `json:"choices"`
-	Usage struct {
-		PromptTokens     int `json:"prompt_tokens"`
-		CompletionTokens int `json:"completion_tokens"`
-		TotalTokens      int `json:"total_tokens"`
-	} `json:"usage"`
+	ID             string             `json:"id"`
+	Object         string             `json:"object"`
+	Created        int                `json:"created"`
+	Model          string             `json:"model"`
+	Choices        []CompletionChoice `json:"choices"`
+	Usage          Usage              `json:"usage"`
+	ResponseExtras `json:"-"`
 }
 
 type ImageResponse struct {
-	Created int `json:"created"`
-	Data    []struct {
-		URL     string `json:"url"`
-		B64JSON string `json:"b64_json"`
-	} `json:"data"`
+	Created        int         `json:"created"`
+	Data           []ImageData `json:"data"`
+	ResponseExtras `json:"-"`
 }
 
 type ImageEditResponse struct {
-	Created int `json:"created"`
-	Data    []struct {
-		URL     string `json:"url"`
-		B64JSON string `json:"b64_json"`
-	} `json:"data"`
+	Created        int         `json:"created"`
+	Data           []ImageData `json:"data"`
+	ResponseExtras `json:"-"`
 }
 
 type ImageVariationResponse struct {
-	Created int `json:"created"`
-	Data    []struct {
-		URL     string `json:"url"`
-		B64JSON string `json:"b64_json"`
-	} `json:"data"`
+	Created        int         `json:"created"`
+	Data           []ImageData `json:"data"`
+	ResponseExtras `json:"-"`
 }
 
 type EmbeddingResponse struct {
-	Object string `json:"object"`
-	Model  string `json:"model"`
-	Data   []struct {
-		Index     int       `json:"index"`
-		Object    string    `json:"object"`
-		Embedding []float64 `json:"embedding"`
-	} `json:"data"`
-	Usage struct {
-		PromptTokens int `json:"prompt_tokens"`
-		TotalTokens  int `json:"total_tokens"`
-	} `json:"usage"`
+	Object string          `json:"object"`
+	Model  string          `json:"model"`
+	Data   []EmbeddingData `json:"data"`
+	Usage  Usage           `json:"usage"`
+	// Cached reports whether the response was served from a cache, see WithResponseCache.
+	Cached         bool `json:"-"`
+	ResponseExtras `json:"-"`
 }
 
 type TranscriptionResponse struct {
-	Text string `json:"text"`
+	Text           string `json:"text"`
+	ResponseExtras `json:"-"`
 }
 
 type TranslationResponse struct {
-	Text string `json:"text"`
+	Text           string `json:"text"`
+	ResponseExtras `json:"-"`
 }
 
 type FilesResponse struct {
-	Object string `json:"object"`
-	Data   []File `json:"data"`
+	Object         string `json:"object"`
+	Data           []File `json:"data"`
+	ResponseExtras `json:"-"`
 }
 
 type DeleteFileResponse struct {
-	ID      string `json:"id"`
-	Object  string `json:"object"`
-	Deleted bool   `json:"deleted"`
+	ID             string `json:"id"`
+	Object         string `json:"object"`
+	Deleted        bool   `json:"deleted"`
+	ResponseExtras `json:"-"`
 }
 
 type FineTunesResponse struct {
-	Object string     `json:"object"`
-	Data   []FineTune `json:"data"`
+	Object         string     `json:"object"`
+	Data           []FineTune `json:"data"`
+	ResponseExtras `json:"-"`
 }
 
 type FineTuneEventsResponse struct {
-	Object string          `json:"object"`
-	Data   []FineTuneEvent `json:"data"`
+	Object         string          `json:"object"`
+	Data           []FineTuneEvent `json:"data"`
+	ResponseExtras `json:"-"`
 }
 
 type ModerationResponse struct {
-	ID      string `json:"id"`
-	Model   string `json:"model"`
-	Results []struct {
-		Flagged    bool `json:"flagged"`
-		Categories struct {
-			Hate            bool `json:"hate"`
-			HateThreatening bool `json:"hate/threatening"`
-			SelfHarm        bool `json:"self-harm"`
-			Sexual          bool `json:"sexual"`
-			SexualMinors    bool `json:"sexual/minors"`
-			Violence        bool `json:"violence"`
-			ViolenceGraphic bool `json:"violence/graphic"`
-		} `json:"categories"`
-		CategoryScores struct {
-			Hate            float64 `json:"hate"`
-			HateThreatening float64 `json:"hate/threatening"`
-			SelfHarm        float64 `json:"self-harm"`
-			Sexual          float64 `json:"sexual"`
-			SexualMinors    float64 `json:"sexual/minors"`
-			Violence        float64 `json:"violence"`
-			ViolenceGraphic float64 `json:"violence/graphic"`
-		} `json:"category_scores"`
-	} `json:"results"`
+	ID      string             `json:"id"`
+	Model   string             `json:"model"`
+	Results []ModerationResult `json:"results"`
+	// Cached reports whether the response was served from a cache, see WithResponseCache.
+	Cached         bool `json:"-"`
+	ResponseExtras `json:"-"`
+}
+
+// FirstText returns the message content of the first choice, or an empty string if there are no choices.
+func (r ChatCompletionResponse) FirstText() string {
+	if len(r.Choices) == 0 {
+		return ""
+	}
+
+	return r.Choices[0].Message.Content
+}
+
+// FirstText returns the text of the first choice, or an empty string if there are no choices.
+func (r CompletionResponse) FirstText() string {
+	if len(r.Choices) == 0 {
+		return ""
+	}
+
+	return r.Choices[0].Text
+}
+
+// FirstText returns the text of the first choice, or an empty string if there are no choices.
+func (r EditResponse) FirstText() string {
+	if len(r.Choices) == 0 {
+		return ""
+	}
+
+	return r.Choices[0].Text
+}
+
+// Flagged reports whether any of the inputs was flagged.
+func (r ModerationResponse) Flagged() bool {
+	for _, result := range r.Results {
+		if result.Flagged {
+			return true
+		}
+	}
+
+	return false
 }
@@ -0,0 +1,322 @@
+package openai
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultResponseCacheTTL        = time.Hour
+	defaultResponseCacheMaxEntries = 10_000
+)
+
+// CacheStore stores the responses of a ResponseCache by key.
+type CacheStore interface {
+	// Get returns the value of the key. ok is false if the key is missing or expired.
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// ResponseCacheConfig configures a ResponseCache. Zero values are replaced by the defaults.
+type ResponseCacheConfig struct {
+	// Store keeps the responses. Defaults to a MemoryCacheStore of 10000 entries.
+	Store CacheStore
+	// Operations maps the cached operations to the TTL of their responses, 0 for the default TTL.
+	// Defaults to completions, chat completions, embeddings and moderations.
+	Operations map[string]time.Duration
+	// TTL is how long responses are reused by default. Defaults to 1 hour.
+	TTL time.Duration
+}
+
+// ResponseCache reuses the responses of identical requests, keyed by their URL, headers and canonical JSON body.
+// Completions and chat completions are only cached with a temperature of 0, since others are not deterministic.
+// Cached responses have their Cached field set. Only successful responses are cached.
+type ResponseCache struct {
+	store      CacheStore
+	operations map[string]time.Duration
+}
+
+// NewResponseCache creates a ResponseCache with the given configuration.
+func NewResponseCache(config ResponseCacheConfig) *ResponseCache {
+	if config.Store == nil {
+		config.Store = NewMemoryCacheStore(defaultResponseCacheMaxEntries)
+	}
+	if config.TTL <= 0 {
+		config.TTL = defaultResponseCacheTTL
+	}
+	if config.Operations == nil {
+		config.Operations = map[string]time.Duration{
+			OperationCompletions:     0,
+			OperationChatCompletions: 0,
+			OperationEmbeddings:      0,
+			OperationModerations:     0,
+		}
+	}
+
+	operations := make(map[string]time.Duration, len(config.Operations))
+	for operation, ttl := range config.Operations {
+		if ttl <= 0 {
+			ttl = config.TTL
+		}
+		operations[operation] = ttl
+	}
+
+	return &ResponseCache{store: config.Store, operations: operations}
+}
+
+// WithResponseCache reuses the responses of identical requests. It runs before the middlewares,
+// so cached responses are not logged, metered or counted against budgets.
+// WithCacheBypass skips the cache for a single call.
+func WithResponseCache(cache *ResponseCache) ClientOption {
+	return func(c *Client) {
+		c.cache = cache
+	}
+}
+
+// wrap returns a handler serving cacheable requests from the cache.
+// If bypass is set, the cached response is ignored but replaced by the new one.
+func (c *ResponseCache) wrap(next Handler, bypass bool) Handler {
+	return func(op Operation, req *http.Request) (*http.Response, error) {
+		ttl, ok := c.operations[op.Name]
+		if !ok || !deterministic(op) {
+			return next(op, req)
+		}
+
+		body := requestBody(req)
+		if body == nil {
+			return next(op, req)
+		}
+
+		key := cacheKey(req, body)
+		ctx := req.Context()
+
+		if !bypass {
+			if cached, ok, err := c.store.Get(ctx, key); err == nil && ok {
+				return cachedResponse(req, cached), nil
+			}
+		}
+
+		resp, err := next(op, req)
+		if err != nil || resp.StatusCode != http.StatusOK {
+			return resp, err
+		}
+
+		data, err := readResponseBody(resp)
+		if err != nil {
+			return resp, err
+		}
+
+		_ = c.store.Set(ctx, key, data, ttl)
+
+		return resp, nil
+	}
+}
+
+// deterministic reports whether the request always gets the same response.
+func deterministic(op Operation) bool {
+	var temperature *float64
+
+	switch payload := op.Payload.(type) {
+	case ChatCompletionRequest:
+		temperature = payload.Temperature
+	case CompletionRequest:
+		temperature = payload.Temperature
+	default:
+		return true
+	}
+
+	return temperature != nil && *temperature == 0
+}
+
+// cacheKey hashes the method, URL, headers and body of the request.
+// The headers keep apart the responses of different accounts in shared stores and of calls with other extra headers.
+// JSON bodies are canonicalized first, so the field order does not matter.
+func cacheKey(req *http.Request, body []byte) string {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err == nil {
+		if canonical, err := json.Marshal(value); err == nil {
+			body = canonical
+		}
+	}
+
+	hash := sha256.New()
+	_, _ = io.WriteString(hash, req.Method+" "+req.URL.String()+"\n")
+
+	keys := make([]string, 0, len(req.Header))
+	for key := range req.Header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		_, _ = fmt.Fprintf(hash, "%s: %q\n", key, req.Header[key])
+	}
+
+	_, _ = hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func (r *CompletionResponse) setCached() {
+	r.Cached = true
+}
+
+func (r *EmbeddingResponse) setCached() {
+	r.Cached = true
+}
+
+func (r *ModerationResponse) setCached() {
+	r.Cached = true
+}
+
+// MemoryCacheStore keeps up to a maximum number of entries in memory, evicting the least recently used ones.
+type MemoryCacheStore struct {
+	maxEntries int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// lru holds the entries from the most recently used one.
+	lru *list.List
+}
+
+type memoryCacheEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewMemoryCacheStore creates a MemoryCacheStore of up to maxEntries entries, 0 for no limit.
+func NewMemoryCacheStore(maxEntries int) *MemoryCacheStore {
+	return &MemoryCacheStore{
+		maxEntries: maxEntries,
+		now:        time.Now,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+func (s *MemoryCacheStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, ok := s.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	entry := element.Value.(*memoryCacheEntry)
+
+	if !s.now().Before(entry.expiresAt) {
+		s.lru.Remove(element)
+		delete(s.entries, key)
+		return nil, false, nil
+	}
+
+	s.lru.MoveToFront(element)
+
+	return entry.value, true, nil
+}
+
+func (s *MemoryCacheStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := &memoryCacheEntry{key: key, value: value, expiresAt: s.now().Add(ttl)}
+
+	if element, ok := s.entries[key]; ok {
+		element.Value = entry
+		s.lru.MoveToFront(element)
+		return nil
+	}
+
+	s.entries[key] = s.lru.PushFront(entry)
+
+	for s.maxEntries > 0 && s.lru.Len() > s.maxEntries {
+		oldest := s.lru.Back()
+		s.lru.Remove(oldest)
+		delete(s.entries, oldest.Value.(*memoryCacheEntry).key)
+	}
+
+	return nil
+}
+
+// FileCacheStore keeps every entry in a file of a directory, so entries survive restarts.
+// Expired entries are removed when they are read.
+type FileCacheStore struct {
+	dir string
+	now func() time.Time
+}
+
+// NewFileCacheStore creates a FileCacheStore in the directory, creating it if needed.
+func NewFileCacheStore(dir string) (*FileCacheStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &FileCacheStore{dir: dir, now: time.Now}, nil
+}
+
+// Get reads the entry file, made of the expiration time in Unix nanoseconds followed by the value.
+func (s *FileCacheStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	data, err := os.ReadFile(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(data) < 8 || s.now().UnixNano() >= int64(binary.LittleEndian.Uint64(data)) {
+		_ = os.Remove(s.path(key))
+		return nil, false, nil
+	}
+
+	return data[8:], true, nil
+}
+
+// Set atomically replaces the entry file.
+func (s *FileCacheStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	data := binary.LittleEndian.AppendUint64(make([]byte, 0, 8+len(value)), uint64(s.now().Add(ttl).UnixNano()))
+	data = append(data, value...)
+
+	file, err := os.CreateTemp(s.dir, "entry.*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err = file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err = file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), s.path(key))
+}
+
+// path returns the file of the key. Keys are hashed, so any key is a valid file name.
+func (s *FileCacheStore) path(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(hash[:]))
+}
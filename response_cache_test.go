@@ -0,0 +1,233 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_WithResponseCache(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+
+		switch r.URL.Path {
+		case "/embeddings":
+			_, _ = w.Write([]byte(`{"data":[{"index":0,"embedding":[0.5]}]}`))
+		case "/chat/completions":
+			_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"4"}}]}`))
+		case "/moderations":
+			_, _ = w.Write([]byte(`{"results":[{"flagged":false}]}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	cache := NewResponseCache(ResponseCacheConfig{
+		Operations: map[string]time.Duration{OperationEmbeddings: 0, OperationChatCompletions: 0},
+	})
+
+	client := NewClient("test", WithBaseURL(server.URL), WithResponseCache(cache))
+	ctx := context.Background()
+
+	embedding := EmbeddingRequest{Model: "ada", Input: []string{"test"}}
+	chat := ChatCompletionRequest{Model: "gpt-4", Messages: []ChatCompletionRequestMessage{{Role: ChatRoleUser, Content: "2+2?"}}}
+	deterministicChat := chat
+	deterministicChat.Temperature = Float(0)
+
+	tests := []struct {
+		name       string
+		call       func(options ...RequestOption) (bool, error)
+		options    []RequestOption
+		wantCached bool
+	}{
+		{
+			name: "embedding",
+			call: func(options ...RequestOption) (bool, error) {
+				resp, err := client.Embedding(ctx, embedding, options...)
+				return resp.Cached, err
+			},
+			wantCached: true,
+		},
+		{
+			name: "embedding with bypass",
+			call: func(options ...RequestOption) (bool, error) {
+				resp, err := client.Embedding(ctx, embedding, options...)
+				return resp.Cached, err
+			},
+			options: []RequestOption{WithCacheBypass()},
+		},
+		{
+			name: "chat completion with temperature 0",
+			call: func(options ...RequestOption) (bool, error) {
+				resp, err := client.ChatCompletion(ctx, deterministicChat, options...)
+				return resp.Cached, err
+			},
+			wantCached: true,
+		},
+		{
+			name: "chat completion with default temperature",
+			call: func(options ...RequestOption) (bool, error) {
+				resp, err := client.ChatCompletion(ctx, chat, options...)
+				return resp.Cached, err
+			},
+		},
+		{
+			name: "moderation not enabled",
+			call: func(options ...RequestOption) (bool, error) {
+				resp, err := client.Moderation(ctx, ModerationRequest{Input: []string{"test"}}, options...)
+				return resp.Cached, err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		if _, err := tt.call(); err != nil {
+			t.Fatal(err)
+		}
+
+		before := calls.Load()
+
+		cached, err := tt.call(tt.options...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if cached != tt.wantCached || (calls.Load() == before) != tt.wantCached {
+			t.Fatalf("%s: expected cached to be %v, got %v after %d calls", tt.name, tt.wantCached, cached, calls.Load()-before)
+		}
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	t.Parallel()
+
+	a := httptest.NewRequest(http.MethodPost, "https://api.openai.com/v1/embeddings", nil)
+	b := httptest.NewRequest(http.MethodPost, "https://api.openai.com/v1/moderations", nil)
+
+	if cacheKey(a, []byte(`{"model":"ada","input":["x"]}`)) != cacheKey(a, []byte(`{"input": ["x"], "model": "ada"}`)) {
+		t.Fatal("expected the keys of equivalent bodies to be equal")
+	}
+
+	if cacheKey(a, []byte(`{"model":"ada"}`)) == cacheKey(b, []byte(`{"model":"ada"}`)) {
+		t.Fatal("expected the keys of different endpoints to differ")
+	}
+
+	c := a.Clone(a.Context())
+	c.Header.Set("Authorization", "Bearer other")
+
+	if cacheKey(a, []byte(`{"model":"ada"}`)) == cacheKey(c, []byte(`{"model":"ada"}`)) {
+		t.Fatal("expected the keys of different credentials to differ")
+	}
+
+	d := a.Clone(a.Context())
+	d.Header.Set("X-Feature", "beta")
+
+	if cacheKey(a, []byte(`{"model":"ada"}`)) == cacheKey(d, []byte(`{"model":"ada"}`)) {
+		t.Fatal("expected the keys of different extra headers to differ")
+	}
+}
+
+func TestClient_WithResponseCache_Credentials(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		_, _ = w.Write([]byte(`{"data":[{"index":0,"embedding":[0.5]}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	// Clients of different accounts share the cache store.
+	cache := NewResponseCache(ResponseCacheConfig{})
+	request := EmbeddingRequest{Model: "ada", Input: []string{"test"}}
+
+	for _, apiKey := range []string{"key-1", "key-2"} {
+		client := NewClient(apiKey, WithBaseURL(server.URL), WithResponseCache(cache))
+
+		resp, err := client.Embedding(context.Background(), request)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.Cached {
+			t.Fatalf("expected the response cached for another API key not to be reused by %s", apiKey)
+		}
+	}
+
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("expected 2 calls, got %d", got)
+	}
+}
+
+func TestCacheStores(t *testing.T) {
+	t.Parallel()
+
+	fileStore, err := NewFileCacheStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	memoryStore := NewMemoryCacheStore(2)
+
+	now := time.Now()
+	fileStore.now = func() time.Time { return now }
+	memoryStore.now = fileStore.now
+
+	ctx := context.Background()
+
+	for _, store := range []CacheStore{memoryStore, fileStore} {
+		if err = store.Set(ctx, "a", []byte("1"), time.Minute); err != nil {
+			t.Fatal(err)
+		}
+		if err = store.Set(ctx, "b", []byte("2"), time.Hour); err != nil {
+			t.Fatal(err)
+		}
+
+		if value, ok, err := store.Get(ctx, "a"); err != nil || !ok || string(value) != "1" {
+			t.Fatalf("%T: expected a to be 1, got %q, %v, %v", store, value, ok, err)
+		}
+	}
+
+	// b is the least recently used entry.
+	if err = memoryStore.Set(ctx, "c", []byte("3"), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok, _ := memoryStore.Get(ctx, "b"); ok {
+		t.Fatal("expected b to be evicted")
+	}
+
+	now = now.Add(2 * time.Minute)
+
+	for _, store := range []CacheStore{memoryStore, fileStore} {
+		if _, ok, _ := store.Get(ctx, "a"); ok {
+			t.Fatalf("%T: expected a to be expired", store)
+		}
+	}
+
+	if value, ok, _ := fileStore.Get(ctx, "b"); !ok || string(value) != "2" {
+		t.Fatalf("expected b to be 2, got %q", value)
+	}
+}
+
+func TestMemoryCacheStore_Unbounded(t *testing.T) {
+	t.Parallel()
+
+	store := NewMemoryCacheStore(0)
+	ctx := context.Background()
+
+	if err := store.Set(ctx, "a", []byte("1"), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	if value, ok, err := store.Get(ctx, "a"); err != nil || !ok || string(value) != "1" {
+		t.Fatalf("expected a store without limit to keep a, got %q, %v, %v", value, ok, err)
+	}
+}
@@ -0,0 +1,37 @@
+package openai
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestChatCompletionResponse_FirstText(t *testing.T) {
+	t.Parallel()
+
+	data := `{
+		"id": "chatcmpl-123",
+		"object": "chat.completion",
+		"created": 1677652288,
+		"model": "gpt-3.5-turbo",
+		"choices": [{"index": 0, "message": {"role": "assistant", "content": "Hello there!"}, "finish_reason": "stop"}],
+		"usage": {"prompt_tokens": 9, "completion_tokens": 12, "total_tokens": 21}
+	}`
+
+	var resp ChatCompletionResponse
+
+	if err := json.Unmarshal([]byte(data), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := resp.FirstText(), "Hello there!"; got != want {
+		t.Fatalf("expected first text to be %q, got %q", want, got)
+	}
+
+	if got, want := resp.Usage, (Usage{PromptTokens: 9, CompletionTokens: 12, TotalTokens: 21}); got != want {
+		t.Fatalf("expected usage to be %+v, got %+v", want, got)
+	}
+
+	if got := (ChatCompletionResponse{}).FirstText(); got != "" {
+		t.Fatalf("expected empty first text, got %q", got)
+	}
+}
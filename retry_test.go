@@ -0,0 +1,34 @@
+package openai
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryDelay(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		retryAfter string
+		want       time.Duration
+	}{
+		{name: "retry after", retryAfter: "2", want: 2 * time.Second},
+		{name: "capped retry after", retryAfter: "86400", want: retryMaxDelay},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			resp := &http.Response{Header: http.Header{"Retry-After": []string{tc.retryAfter}}}
+
+			if got := retryDelay(0, resp); got != tc.want {
+				t.Fatalf("expected delay to be %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
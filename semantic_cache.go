@@ -0,0 +1,262 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/KirillMironov/openai/vector"
+)
+
+// cacheStatusHeader marks the responses served from a cache, so Client methods can report them as cached.
+const (
+	cacheStatusHeader = "X-Openai-Go-Cache"
+	cacheStatusHit    = "hit"
+)
+
+const (
+	defaultSemanticCacheThreshold  = 0.95
+	defaultSemanticCacheTTL        = 24 * time.Hour
+	defaultSemanticCacheMaxEntries = 10_000
+)
+
+// SemanticCacheEmbedder embeds the questions of a SemanticCache, e.g. Embedder.
+type SemanticCacheEmbedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// SemanticCacheEntry is a cached chat completion.
+type SemanticCacheEntry struct {
+	// Scope identifies the parameters, earlier messages and credentials the response was created for.
+	Scope string
+	// Vector is the embedding of the last user message.
+	Vector []float32
+	// Response is the JSON body of the response.
+	Response  []byte
+	CreatedAt time.Time
+}
+
+// SemanticCacheStore stores the entries of a SemanticCache.
+type SemanticCacheStore interface {
+	// Nearest returns the entry of the scope created after since whose vector is the most similar to the given one,
+	// with its cosine similarity. ok is false if the scope has no such entry.
+	Nearest(ctx context.Context, scope string, vector []float32, since time.Time) (entry SemanticCacheEntry, similarity float32, ok bool, err error)
+	Put(ctx context.Context, entry SemanticCacheEntry) error
+}
+
+// SemanticCacheConfig configures a SemanticCache. Zero values are replaced by the defaults.
+type SemanticCacheConfig struct {
+	// Embedder embeds the last user message of the requests. Required.
+	Embedder SemanticCacheEmbedder
+	// Store keeps the entries. Defaults to a MemorySemanticCacheStore of 10000 entries.
+	Store SemanticCacheStore
+	// Threshold is the minimum cosine similarity of a cached question to be reused. Defaults to 0.95.
+	Threshold float32
+	// TTL is how long entries are reused. Defaults to 24 hours.
+	TTL time.Duration
+}
+
+// SemanticCache answers ChatCompletion calls whose last user message is similar to a previous one
+// with the same parameters, earlier messages and credentials from the previous response, without calling the API.
+// Cached responses have their Cached field set.
+// Requests whose last message is not a text user message are not cached.
+type SemanticCache struct {
+	config SemanticCacheConfig
+	now    func() time.Time
+}
+
+// NewSemanticCache creates a SemanticCache with the given configuration.
+func NewSemanticCache(config SemanticCacheConfig) *SemanticCache {
+	if config.Store == nil {
+		config.Store = NewMemorySemanticCacheStore(defaultSemanticCacheMaxEntries)
+	}
+	if config.Threshold <= 0 {
+		config.Threshold = defaultSemanticCacheThreshold
+	}
+	if config.TTL <= 0 {
+		config.TTL = defaultSemanticCacheTTL
+	}
+
+	return &SemanticCache{config: config, now: time.Now}
+}
+
+// WithSemanticCache serves similar ChatCompletion calls from the cache. Like WithResponseCache,
+// it runs before the middlewares, so cached responses are not logged, metered or counted against budgets.
+// WithCacheBypass skips the cache for a single call.
+func WithSemanticCache(cache *SemanticCache) ClientOption {
+	return func(c *Client) {
+		c.semanticCache = cache
+	}
+}
+
+// wrap returns a handler serving similar ChatCompletion calls from the cache.
+// If bypass is set, the cached response is ignored but the new one is still stored.
+// Failures of the cache itself are ignored, the request is then sent as is.
+func (c *SemanticCache) wrap(next Handler, bypass bool) Handler {
+	return func(op Operation, req *http.Request) (*http.Response, error) {
+		request, ok := op.Payload.(ChatCompletionRequest)
+		if op.Name != OperationChatCompletions || !ok {
+			return next(op, req)
+		}
+
+		scope, question, ok := semanticCacheKey(req, request)
+		if !ok {
+			return next(op, req)
+		}
+
+		ctx := req.Context()
+
+		vectors, err := c.config.Embedder.Embed(ctx, []string{question})
+		if err != nil || len(vectors) != 1 {
+			return next(op, req)
+		}
+
+		now := c.now()
+
+		if !bypass {
+			entry, similarity, ok, err := c.config.Store.Nearest(ctx, scope, vectors[0], now.Add(-c.config.TTL))
+			if err == nil && ok && similarity >= c.config.Threshold {
+				return cachedResponse(req, entry.Response), nil
+			}
+		}
+
+		resp, err := next(op, req)
+		if err != nil || resp.StatusCode != http.StatusOK {
+			return resp, err
+		}
+
+		body, err := readResponseBody(resp)
+		if err != nil {
+			return resp, err
+		}
+
+		_ = c.config.Store.Put(ctx, SemanticCacheEntry{Scope: scope, Vector: vectors[0], Response: body, CreatedAt: now})
+
+		return resp, nil
+	}
+}
+
+// semanticCacheKey returns the scope of the request and its question, the text of its last message if it is a user one.
+// The scope is the cache key of the request without the question, so answers are only reused
+// for the same parameters, earlier messages and credentials.
+func semanticCacheKey(req *http.Request, request ChatCompletionRequest) (scope, question string, ok bool) {
+	if len(request.Messages) == 0 {
+		return "", "", false
+	}
+
+	last := request.Messages[len(request.Messages)-1]
+	if last.Role != ChatRoleUser || last.Content == "" || len(last.MultiContent) > 0 {
+		return "", "", false
+	}
+
+	request.Messages = request.Messages[:len(request.Messages)-1]
+
+	data, err := json.Marshal(request)
+	if err != nil {
+		return "", "", false
+	}
+
+	if data, err = mergeJSON(data, request.ExtraBody); err != nil {
+		return "", "", false
+	}
+
+	return cacheKey(req, data), last.Content, true
+}
+
+// cachedResponse returns a response with the cached body, marked as a cache hit.
+func cachedResponse(req *http.Request, body []byte) *http.Response {
+	header := make(http.Header)
+	header.Set("Content-Type", "application/json")
+	header.Set(cacheStatusHeader, cacheStatusHit)
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+type cachedSetter interface {
+	setCached()
+}
+
+func (r *ChatCompletionResponse) setCached() {
+	r.Cached = true
+}
+
+// MemorySemanticCacheStore keeps up to a maximum number of entries in memory, evicting the oldest ones.
+// Nearest compares the vector with every entry of the scope.
+type MemorySemanticCacheStore struct {
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string][]SemanticCacheEntry
+	// order holds the scopes of the entries from the oldest one, for eviction.
+	order []string
+}
+
+// NewMemorySemanticCacheStore creates a MemorySemanticCacheStore of up to maxEntries entries, 0 for no limit.
+func NewMemorySemanticCacheStore(maxEntries int) *MemorySemanticCacheStore {
+	return &MemorySemanticCacheStore{
+		maxEntries: maxEntries,
+		entries:    make(map[string][]SemanticCacheEntry),
+	}
+}
+
+func (s *MemorySemanticCacheStore) Nearest(_ context.Context, scope string, v []float32, since time.Time) (SemanticCacheEntry, float32, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var (
+		best       SemanticCacheEntry
+		similarity float32
+		found      bool
+	)
+
+	for _, entry := range s.entries[scope] {
+		if entry.CreatedAt.Before(since) || len(entry.Vector) != len(v) {
+			continue
+		}
+
+		if score := vector.CosineSimilarity(v, entry.Vector); !found || score > similarity {
+			best, similarity, found = entry, score, true
+		}
+	}
+
+	return best, similarity, found, nil
+}
+
+func (s *MemorySemanticCacheStore) Put(_ context.Context, entry SemanticCacheEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[entry.Scope] = append(s.entries[entry.Scope], entry)
+
+	if s.maxEntries <= 0 {
+		return nil
+	}
+
+	s.order = append(s.order, entry.Scope)
+
+	// Entries of a scope are appended in order, so the oldest entry is the first one of the oldest scope.
+	for len(s.order) > s.maxEntries {
+		oldest := s.order[0]
+		s.order = s.order[1:]
+
+		if s.entries[oldest] = s.entries[oldest][1:]; len(s.entries[oldest]) == 0 {
+			delete(s.entries, oldest)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,192 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type embedderFunc func(ctx context.Context, texts []string) ([][]float32, error)
+
+func (f embedderFunc) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return f(ctx, texts)
+}
+
+func TestClient_WithSemanticCache(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		_, _ = w.Write([]byte(`{"id":"chatcmpl-1","choices":[{"message":{"role":"assistant","content":"Reset it in the settings."}}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	// Questions mentioning a password are similar to each other.
+	embedder := embedderFunc(func(_ context.Context, texts []string) ([][]float32, error) {
+		if strings.Contains(texts[0], "password") {
+			return [][]float32{{1, 0.1}}, nil
+		}
+		return [][]float32{{0, 1}}, nil
+	})
+
+	cache := NewSemanticCache(SemanticCacheConfig{Embedder: embedder, TTL: time.Hour})
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	meter := NewMeter(PriceTable{})
+
+	client := NewClient("test", WithBaseURL(server.URL), WithMeter(meter), WithSemanticCache(cache))
+	// other shares the cache with client, but uses another API key.
+	other := NewClient("other", WithBaseURL(server.URL), WithSemanticCache(cache))
+
+	support := []ChatCompletionRequestMessage{{Role: ChatRoleSystem, Content: "support"}}
+
+	ask := func(client *Client, history []ChatCompletionRequestMessage, question string, options ...RequestOption) ChatCompletionResponse {
+		t.Helper()
+
+		messages := append(append([]ChatCompletionRequestMessage(nil), history...), ChatCompletionRequestMessage{Role: ChatRoleUser, Content: question})
+
+		resp, err := client.ChatCompletion(context.Background(), ChatCompletionRequest{Model: "gpt-4", Messages: messages}, options...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return resp
+	}
+
+	if resp := ask(client, support, "How do I reset my password?"); resp.Cached {
+		t.Fatal("expected the first response not to be cached")
+	}
+
+	resp := ask(client, support, "how to reset password")
+	if !resp.Cached || resp.FirstText() != "Reset it in the settings." || resp.ID != "chatcmpl-1" {
+		t.Fatalf("expected the cached response, got %+v", resp)
+	}
+
+	if got := meter.Snapshot().Total().Requests; got != 1 {
+		t.Fatalf("expected cached responses not to be metered, got %d requests", got)
+	}
+
+	tests := []struct {
+		name     string
+		client   *Client
+		history  []ChatCompletionRequestMessage
+		question string
+		options  []RequestOption
+		advance  time.Duration
+	}{
+		{name: "bypass", client: client, history: support, question: "how to reset password", options: []RequestOption{WithCacheBypass()}},
+		{name: "different question", client: client, history: support, question: "What are your opening hours?"},
+		{name: "different system prompt", client: client, history: []ChatCompletionRequestMessage{{Role: ChatRoleSystem, Content: "sales"}},
+			question: "How do I reset my password?"},
+		{name: "different history", client: client, history: append(support,
+			ChatCompletionRequestMessage{Role: ChatRoleUser, Content: "I use the mobile app."},
+			ChatCompletionRequestMessage{Role: ChatRoleAssistant, Content: "How can I help?"},
+		), question: "How do I reset my password?"},
+		{name: "different credentials", client: other, history: support, question: "How do I reset my password?"},
+		{name: "expired entry", client: client, history: support, question: "How do I reset my password?", advance: 2 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		now = now.Add(tt.advance)
+		before := calls.Load()
+
+		if resp = ask(tt.client, tt.history, tt.question, tt.options...); resp.Cached || calls.Load() != before+1 {
+			t.Fatalf("%s: expected the API to be called", tt.name)
+		}
+	}
+}
+
+func TestClient_WithSemanticCache_Parameters(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		_, _ = w.Write([]byte(`{"id":"chatcmpl-1","choices":[{"message":{"role":"assistant","content":"Reset"}}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	embedder := embedderFunc(func(context.Context, []string) ([][]float32, error) {
+		return [][]float32{{1, 0}}, nil
+	})
+
+	client := NewClient("test", WithBaseURL(server.URL),
+		WithSemanticCache(NewSemanticCache(SemanticCacheConfig{Embedder: embedder})))
+
+	question := []ChatCompletionRequestMessage{{Role: ChatRoleUser, Content: "How do I reset my password?"}}
+
+	// The same question with other generation parameters must not get an answer generated for the first ones.
+	requests := []ChatCompletionRequest{
+		{Model: "gpt-4", Messages: question, MaxTokens: 5},
+		{Model: "gpt-4", Messages: question, MaxTokens: 100},
+		{Model: "gpt-4", Messages: question, MaxTokens: 100, RequestExtras: RequestExtras{
+			ExtraBody: map[string]any{"response_format": map[string]string{"type": "json_object"}},
+		}},
+	}
+
+	for _, request := range requests {
+		resp, err := client.ChatCompletion(context.Background(), request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Cached {
+			t.Fatalf("expected request %+v not to be served from the cache", request)
+		}
+	}
+
+	if got := calls.Load(); got != int32(len(requests)) {
+		t.Fatalf("expected %d calls, got %d", len(requests), got)
+	}
+}
+
+func TestMemorySemanticCacheStore(t *testing.T) {
+	t.Parallel()
+
+	store := NewMemorySemanticCacheStore(2)
+	ctx := context.Background()
+	now := time.Now()
+
+	for i, scope := range []string{"a", "b", "a"} {
+		entry := SemanticCacheEntry{Scope: scope, Vector: []float32{1, float32(i)}, Response: []byte{byte(i)}, CreatedAt: now}
+		if err := store.Put(ctx, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entry, similarity, ok, err := store.Nearest(ctx, "a", []float32{1, 0}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first entry of scope a was evicted, the nearest one left is the third entry.
+	if !ok || entry.Response[0] != 2 || similarity >= 1 {
+		t.Fatalf("expected the third entry, got %+v with similarity %v", entry, similarity)
+	}
+
+	if _, _, ok, _ = store.Nearest(ctx, "a", []float32{1, 0}, now.Add(time.Second)); ok {
+		t.Fatal("expected entries created before since to be ignored")
+	}
+}
+
+func TestMemorySemanticCacheStore_Unbounded(t *testing.T) {
+	t.Parallel()
+
+	store := NewMemorySemanticCacheStore(0)
+	ctx := context.Background()
+
+	if err := store.Put(ctx, SemanticCacheEntry{Scope: "a", Vector: []float32{1, 0}, CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, ok, _ := store.Nearest(ctx, "a", []float32{1, 0}, time.Time{}); !ok {
+		t.Fatal("expected a store without limit to keep its entries")
+	}
+}
@@ -0,0 +1,72 @@
+package textsplit
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"strings"
+
+	"github.com/KirillMironov/openai/internal/tokens"
+)
+
+// GoSplitter cuts Go source code into chunks of up to Size tokens between top-level declarations,
+// keeping declarations with their doc comments. Consecutive small declarations share a chunk.
+// Declarations too long for a chunk are cut between blocks of lines, then between lines.
+// Source code which does not parse is cut between blocks of lines only.
+type GoSplitter struct {
+	Size    int
+	Overlap int
+}
+
+// NewGoSplitter creates a GoSplitter. Overlap must be lower than size.
+func NewGoSplitter(size, overlap int) *GoSplitter {
+	return &GoSplitter{Size: size, Overlap: overlap}
+}
+
+func (s *GoSplitter) Split(text string) []Chunk {
+	file, err := parser.ParseFile(token.NewFileSet(), "", text, parser.ParseComments)
+	if err != nil {
+		return splitRecursive(text, 0, len(text), s.Size, s.Overlap, paragraphSeparator, lineSeparator)
+	}
+
+	// Declarations are cut at the start of their first line, so units cover the whole file.
+	boundaries := []int{0}
+
+	for _, decl := range file.Decls {
+		pos := decl.Pos()
+		if doc := declDoc(decl); doc != nil {
+			pos = doc.Pos()
+		}
+
+		offset := int(pos) - int(file.FileStart)
+		boundaries = append(boundaries, strings.LastIndexByte(text[:offset], '\n')+1)
+	}
+
+	boundaries = append(boundaries, len(text))
+
+	var declarations []span
+
+	for i := 0; i < len(boundaries)-1; i++ {
+		start, end := trimSpace(text, boundaries[i], boundaries[i+1])
+		if start == end {
+			continue
+		}
+
+		declaration := span{start: start, end: end, tokens: tokens.Estimate(text[start:end])}
+		declarations = append(declarations, units(text, declaration, s.Size, []*regexp.Regexp{paragraphSeparator, lineSeparator, wordSeparator})...)
+	}
+
+	return merge(text, declarations, s.Size, s.Overlap)
+}
+
+func declDoc(decl ast.Decl) *ast.CommentGroup {
+	switch decl := decl.(type) {
+	case *ast.FuncDecl:
+		return decl.Doc
+	case *ast.GenDecl:
+		return decl.Doc
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,47 @@
+package textsplit
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGoSplitter(t *testing.T) {
+	t.Parallel()
+
+	text := `package main
+
+import "fmt"
+
+// Greet says hello.
+func Greet(name string) {
+	fmt.Println("hello", name)
+}
+
+type T struct{}
+
+func main() {
+	Greet("world")
+}
+`
+
+	chunks := NewGoSplitter(30, 0).Split(text)
+	checkOffsets(t, text, chunks)
+
+	var found bool
+	for _, chunk := range chunks {
+		if strings.HasPrefix(chunk.Text, "// Greet says hello.\nfunc Greet") && strings.HasSuffix(chunk.Text, "}") {
+			found = true
+		}
+		if strings.Contains(chunk.Text, "func Greet") && strings.Contains(chunk.Text, "func main") {
+			t.Fatalf("expected declarations exceeding the size together to be in separate chunks, got %q", chunk.Text)
+		}
+	}
+
+	if !found {
+		t.Fatalf("expected Greet with its doc comment in a chunk, got %q", texts(chunks))
+	}
+
+	if chunks = NewGoSplitter(20, 0).Split("not go {"); len(chunks) != 1 || chunks[0].Text != "not go {" {
+		t.Fatalf("expected invalid source to be split as text, got %+v", chunks)
+	}
+}
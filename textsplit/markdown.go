@@ -0,0 +1,112 @@
+package textsplit
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	headingLine = regexp.MustCompile(`^(#{1,6})[ \t]+(.*?)[ \t#]*$`)
+	fenceLine   = regexp.MustCompile("^[ \t]*(```|~~~)")
+)
+
+// MarkdownSplitter cuts a Markdown document into chunks of up to Size tokens which never span two sections.
+// A section starts with its heading, so the first chunk of a section contains it, and every chunk has
+// the headings of its enclosing sections. Sections too long for a chunk are split like RecursiveSplitter does.
+// Headings in fenced code blocks are ignored.
+type MarkdownSplitter struct {
+	Size    int
+	Overlap int
+}
+
+// NewMarkdownSplitter creates a MarkdownSplitter. Overlap must be lower than size.
+func NewMarkdownSplitter(size, overlap int) *MarkdownSplitter {
+	return &MarkdownSplitter{Size: size, Overlap: overlap}
+}
+
+func (s *MarkdownSplitter) Split(text string) []Chunk {
+	var chunks []Chunk
+
+	for _, section := range markdownSections(text) {
+		for _, chunk := range splitRecursive(text, section.start, section.end, s.Size, s.Overlap, paragraphSeparator, sentenceSeparator) {
+			chunk.Headings = section.headings
+			chunks = append(chunks, chunk)
+		}
+	}
+
+	return chunks
+}
+
+type markdownSection struct {
+	start    int
+	end      int
+	headings []string
+}
+
+// markdownSections cuts the text before every heading.
+// Sections made of their heading only are dropped, their title is in the headings of the subsections.
+func markdownSections(text string) []markdownSection {
+	var (
+		sections []markdownSection
+		current  markdownSection
+		// headings holds the titles of the enclosing sections by level.
+		headings   [6]string
+		fenced     bool
+		hasContent bool
+	)
+
+	closeSection := func(end int) {
+		current.end = end
+		if hasContent {
+			sections = append(sections, current)
+		}
+	}
+
+	for offset := 0; offset < len(text); {
+		lineEnd := strings.IndexByte(text[offset:], '\n')
+		if lineEnd < 0 {
+			lineEnd = len(text)
+		} else {
+			lineEnd += offset
+		}
+
+		line := text[offset:lineEnd]
+
+		if fenceLine.MatchString(line) {
+			fenced = !fenced
+		}
+
+		if match := headingLine.FindStringSubmatch(line); match != nil && !fenced {
+			closeSection(offset)
+
+			level := len(match[1]) - 1
+			headings[level] = match[2]
+			for i := level + 1; i < len(headings); i++ {
+				headings[i] = ""
+			}
+
+			current = markdownSection{start: offset, headings: nonEmpty(headings[:level+1])}
+			hasContent = false
+		} else if strings.TrimSpace(line) != "" {
+			hasContent = true
+		}
+
+		offset = lineEnd + 1
+	}
+
+	closeSection(len(text))
+
+	return sections
+}
+
+func nonEmpty(values []string) []string {
+	var result []string
+
+	for _, value := range values {
+		if value != "" {
+			result = append(result, value)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,44 @@
+package textsplit
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdownSplitter(t *testing.T) {
+	t.Parallel()
+
+	text := `Intro text.
+
+# Guide
+
+## Install
+
+Run the installer.
+
+` + "```sh\n# not a heading\nmake install\n```" + `
+
+## Usage
+
+Call the API. Read the answer.
+`
+
+	chunks := NewMarkdownSplitter(100, 0).Split(text)
+	checkOffsets(t, text, chunks)
+
+	if len(chunks) != 3 {
+		t.Fatalf("expected 3 chunks, got %q", texts(chunks))
+	}
+
+	if chunks[0].Text != "Intro text." || len(chunks[0].Headings) != 0 {
+		t.Fatalf("expected the intro without headings, got %+v", chunks[0])
+	}
+
+	if !strings.HasPrefix(chunks[1].Text, "## Install") || !strings.Contains(chunks[1].Text, "make install") {
+		t.Fatalf("expected the install section with its code block, got %q", chunks[1].Text)
+	}
+
+	if got := strings.Join(chunks[2].Headings, " > "); got != "Guide > Usage" {
+		t.Fatalf("expected headings to be Guide > Usage, got %q", got)
+	}
+}
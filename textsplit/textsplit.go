@@ -0,0 +1,184 @@
+// Package textsplit cuts documents into chunks of a bounded number of tokens, e.g. before embedding them.
+// Token counts are estimated, see tokens.Estimate.
+package textsplit
+
+import (
+	"regexp"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/KirillMironov/openai/internal/tokens"
+)
+
+// Chunk is a part of a text. Start and End are the byte offsets of the chunk in the text, so Text is text[Start:End].
+type Chunk struct {
+	Text  string
+	Start int
+	End   int
+	// Headings are the titles of the Markdown sections enclosing the chunk, outermost first.
+	Headings []string
+}
+
+// Splitter cuts a text into chunks.
+type Splitter interface {
+	Split(text string) []Chunk
+}
+
+var (
+	paragraphSeparator = regexp.MustCompile(`\n[ \t]*\n\s*`)
+	sentenceSeparator  = regexp.MustCompile(`[.!?]+["')\]]*\s+|\n\s*`)
+	lineSeparator      = regexp.MustCompile(`\n\s*`)
+	wordSeparator      = regexp.MustCompile(`\s+`)
+)
+
+// TokenSplitter cuts a text into chunks of up to Size tokens on word boundaries.
+// Consecutive chunks share about Overlap tokens, so passages cut in the middle keep some context.
+// A word longer than Size is a chunk of its own.
+type TokenSplitter struct {
+	Size    int
+	Overlap int
+}
+
+// NewTokenSplitter creates a TokenSplitter. Overlap must be lower than size.
+func NewTokenSplitter(size, overlap int) *TokenSplitter {
+	return &TokenSplitter{Size: size, Overlap: overlap}
+}
+
+func (s *TokenSplitter) Split(text string) []Chunk {
+	return merge(text, cut(text, 0, len(text), wordSeparator), s.Size, s.Overlap)
+}
+
+// RecursiveSplitter cuts a text into chunks of up to Size tokens,
+// cutting between paragraphs, then between sentences and lines, and then between words,
+// so chunks break at the most natural boundary available.
+// Consecutive chunks share about Overlap tokens.
+type RecursiveSplitter struct {
+	Size    int
+	Overlap int
+}
+
+// NewRecursiveSplitter creates a RecursiveSplitter. Overlap must be lower than size.
+func NewRecursiveSplitter(size, overlap int) *RecursiveSplitter {
+	return &RecursiveSplitter{Size: size, Overlap: overlap}
+}
+
+func (s *RecursiveSplitter) Split(text string) []Chunk {
+	return splitRecursive(text, 0, len(text), s.Size, s.Overlap, paragraphSeparator, sentenceSeparator)
+}
+
+// splitRecursive splits text[start:end] into units fitting size, cutting at the separators in order and
+// then between words, and merges the units into chunks.
+func splitRecursive(text string, start, end, size, overlap int, separators ...*regexp.Regexp) []Chunk {
+	start, end = trimSpace(text, start, end)
+	if start == end {
+		return nil
+	}
+
+	whole := span{start: start, end: end, tokens: tokens.Estimate(text[start:end])}
+
+	return merge(text, units(text, whole, size, append(separators, wordSeparator)), size, overlap)
+}
+
+// span is a part of a text with its estimated tokens.
+type span struct {
+	start  int
+	end    int
+	tokens int
+}
+
+// units cuts the span at the first separator, and the parts still exceeding size at the next ones.
+// Parts which cannot be cut further are returned as is.
+func units(text string, s span, size int, separators []*regexp.Regexp) []span {
+	if s.tokens <= size || len(separators) == 0 {
+		return []span{s}
+	}
+
+	parts := cut(text, s.start, s.end, separators[0])
+	if len(parts) == 1 {
+		return units(text, s, size, separators[1:])
+	}
+
+	var result []span
+	for _, part := range parts {
+		result = append(result, units(text, part, size, separators[1:])...)
+	}
+
+	return result
+}
+
+// cut splits text[start:end] after every match of the separator.
+// The parts are trimmed of whitespace, empty ones are dropped.
+func cut(text string, start, end int, separator *regexp.Regexp) []span {
+	var (
+		parts []span
+		from  = start
+	)
+
+	add := func(from, to int) {
+		if from, to = trimSpace(text, from, to); from < to {
+			parts = append(parts, span{start: from, end: to, tokens: tokens.Estimate(text[from:to])})
+		}
+	}
+
+	for _, match := range separator.FindAllStringIndex(text[start:end], -1) {
+		add(from, start+match[1])
+		from = start + match[1]
+	}
+
+	add(from, end)
+
+	return parts
+}
+
+// merge groups consecutive units into chunks of up to size tokens overlapping by up to overlap tokens.
+// A chunk spans the text from its first unit to its last one.
+func merge(text string, units []span, size, overlap int) []Chunk {
+	var chunks []Chunk
+
+	for i := 0; i < len(units); {
+		j, total := i, 0
+		for j < len(units) && (j == i || total+units[j].tokens <= size) {
+			total += units[j].tokens
+			j++
+		}
+
+		start, end := units[i].start, units[j-1].end
+		chunks = append(chunks, Chunk{Text: text[start:end], Start: start, End: end})
+
+		if j == len(units) {
+			break
+		}
+
+		// The next chunk starts with the last units of this one, but always after its first unit.
+		k, shared := j, 0
+		for k > i+1 && shared+units[k-1].tokens <= overlap {
+			k--
+			shared += units[k].tokens
+		}
+
+		i = k
+	}
+
+	return chunks
+}
+
+// trimSpace returns the offsets of text[start:end] without its leading and trailing whitespace.
+func trimSpace(text string, start, end int) (int, int) {
+	for start < end {
+		r, size := utf8.DecodeRuneInString(text[start:end])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		start += size
+	}
+
+	for end > start {
+		r, size := utf8.DecodeLastRuneInString(text[start:end])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		end -= size
+	}
+
+	return start, end
+}
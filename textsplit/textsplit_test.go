@@ -0,0 +1,78 @@
+package textsplit
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTokenSplitter(t *testing.T) {
+	t.Parallel()
+
+	// Every word is one token.
+	text := "one two  tri four\nfive six sev"
+
+	chunks := NewTokenSplitter(3, 1).Split(text)
+
+	want := []string{"one two  tri", "tri four\nfive", "five six sev"}
+
+	if len(chunks) != len(want) {
+		t.Fatalf("expected %d chunks, got %+v", len(want), chunks)
+	}
+
+	for i, chunk := range chunks {
+		if chunk.Text != want[i] {
+			t.Fatalf("expected chunk %d to be %q, got %q", i, want[i], chunk.Text)
+		}
+		if text[chunk.Start:chunk.End] != chunk.Text {
+			t.Fatalf("expected chunk %d offsets to match its text, got %+v", i, chunk)
+		}
+	}
+
+	long := strings.Repeat("x", 40)
+	if chunks = NewTokenSplitter(3, 0).Split("a " + long + " b"); len(chunks) != 3 || chunks[1].Text != long {
+		t.Fatalf("expected the long word to be a chunk of its own, got %+v", chunks)
+	}
+}
+
+func checkOffsets(t *testing.T, text string, chunks []Chunk) {
+	t.Helper()
+
+	for i, chunk := range chunks {
+		if text[chunk.Start:chunk.End] != chunk.Text {
+			t.Fatalf("expected chunk %d offsets to match its text, got %+v", i, chunk)
+		}
+	}
+}
+
+func texts(chunks []Chunk) []string {
+	result := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		result[i] = chunk.Text
+	}
+	return result
+}
+
+func TestRecursiveSplitter(t *testing.T) {
+	t.Parallel()
+
+	text := "Cats purr. Dogs bark.\n\nFish swim in the deep blue sea. Birds fly.\n\n" + strings.Repeat("word ", 6)
+
+	chunks := NewRecursiveSplitter(9, 0).Split(text)
+	checkOffsets(t, text, chunks)
+
+	want := []string{
+		"Cats purr. Dogs bark.",
+		"Fish swim in the deep blue sea.",
+		"Birds fly.",
+		"word word word word word word",
+	}
+
+	if got := texts(chunks); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("expected chunks %q, got %q", want, got)
+	}
+
+	chunks = NewRecursiveSplitter(4, 2).Split(strings.Repeat("word ", 6))
+	if got, want := texts(chunks), []string{"word word word word", "word word word word"}; strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("expected overlapping chunks %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,211 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// Tracer starts spans for API calls.
+// It is implemented by adapters to tracing backends, see the otelopenai module for OpenTelemetry.
+type Tracer interface {
+	// Start starts a span with the given name and attributes,
+	// returning a context carrying the span and the span itself.
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// Span is a single traced API call.
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+// Attribute is a span attribute.
+// Value is one of string, bool, int, float64 or a slice of them.
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// Attribute keys following the OpenTelemetry semantic conventions for generative AI systems.
+const (
+	AttributeGenAISystem                  = "gen_ai.system"
+	AttributeGenAIOperationName           = "gen_ai.operation.name"
+	AttributeGenAIRequestModel            = "gen_ai.request.model"
+	AttributeGenAIRequestTemperature      = "gen_ai.request.temperature"
+	AttributeGenAIRequestTopP             = "gen_ai.request.top_p"
+	AttributeGenAIRequestMaxTokens        = "gen_ai.request.max_tokens"
+	AttributeGenAIRequestPresencePenalty  = "gen_ai.request.presence_penalty"
+	AttributeGenAIRequestFrequencyPenalty = "gen_ai.request.frequency_penalty"
+	AttributeGenAIRequestStopSequences    = "gen_ai.request.stop_sequences"
+	AttributeGenAIResponseID              = "gen_ai.response.id"
+	AttributeGenAIResponseModel           = "gen_ai.response.model"
+	AttributeGenAIResponseFinishReasons   = "gen_ai.response.finish_reasons"
+	AttributeGenAIUsageInputTokens        = "gen_ai.usage.input_tokens"
+	AttributeGenAIUsageOutputTokens       = "gen_ai.usage.output_tokens"
+	AttributeServerAddress                = "server.address"
+	AttributeHTTPResponseStatusCode       = "http.response.status_code"
+	AttributeErrorType                    = "error.type"
+)
+
+// WithTracer traces every API call with the given tracer.
+// See TracingMiddleware for details.
+func WithTracer(tracer Tracer) ClientOption {
+	return WithMiddleware(TracingMiddleware(tracer))
+}
+
+// TracingMiddleware starts a span for every API call, named "{operation} {model}",
+// with the request parameters, response metadata and token usage as attributes.
+func TracingMiddleware(tracer Tracer) Middleware {
+	return func(next Handler) Handler {
+		return func(op Operation, req *http.Request) (*http.Response, error) {
+			operationName := genAIOperationName(op.Name)
+
+			spanName := operationName
+			if op.Model != "" {
+				spanName += " " + op.Model
+			}
+
+			ctx, span := tracer.Start(req.Context(), spanName, requestAttributes(op, operationName, req)...)
+			defer span.End()
+
+			resp, err := next(op, req.WithContext(ctx))
+			if err != nil {
+				span.SetAttributes(Attribute{Key: AttributeErrorType, Value: "transport"})
+				span.RecordError(err)
+				return resp, err
+			}
+
+			span.SetAttributes(Attribute{Key: AttributeHTTPResponseStatusCode, Value: resp.StatusCode})
+
+			if resp.StatusCode >= http.StatusBadRequest {
+				span.SetAttributes(Attribute{Key: AttributeErrorType, Value: strconv.Itoa(resp.StatusCode)})
+				span.RecordError(Error{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)})
+				return resp, nil
+			}
+
+			body, err := readResponseBody(resp)
+			if err != nil {
+				span.RecordError(err)
+				return nil, err
+			}
+
+			span.SetAttributes(responseAttributes(body)...)
+
+			return resp, nil
+		}
+	}
+}
+
+// genAIOperationName maps the operation name to the semantic conventions one.
+func genAIOperationName(operation string) string {
+	switch operation {
+	case OperationChatCompletions:
+		return "chat"
+	case OperationCompletions:
+		return "text_completion"
+	case OperationEmbeddings:
+		return "embeddings"
+	default:
+		return operation
+	}
+}
+
+func requestAttributes(op Operation, operationName string, req *http.Request) []Attribute {
+	attrs := []Attribute{
+		{Key: AttributeGenAISystem, Value: "openai"},
+		{Key: AttributeGenAIOperationName, Value: operationName},
+		{Key: AttributeServerAddress, Value: req.URL.Hostname()},
+	}
+
+	if op.Model != "" {
+		attrs = append(attrs, Attribute{Key: AttributeGenAIRequestModel, Value: op.Model})
+	}
+
+	var (
+		temperature, topP, presencePenalty, frequencyPenalty *float64
+		maxTokens                                            int
+		stop                                                 []string
+	)
+
+	switch payload := op.Payload.(type) {
+	case ChatCompletionRequest:
+		temperature, topP, maxTokens, stop = payload.Temperature, payload.TopP, payload.MaxTokens, payload.Stop
+		presencePenalty, frequencyPenalty = payload.PresencePenalty, payload.FrequencyPenalty
+	case CompletionRequest:
+		temperature, topP, maxTokens, stop = payload.Temperature, payload.TopP, payload.MaxTokens, payload.Stop
+		presencePenalty, frequencyPenalty = payload.PresencePenalty, payload.FrequencyPenalty
+	case EditRequest:
+		temperature, topP = payload.Temperature, payload.TopP
+	}
+
+	optional := []struct {
+		key   string
+		value *float64
+	}{
+		{AttributeGenAIRequestTemperature, temperature},
+		{AttributeGenAIRequestTopP, topP},
+		{AttributeGenAIRequestPresencePenalty, presencePenalty},
+		{AttributeGenAIRequestFrequencyPenalty, frequencyPenalty},
+	}
+
+	for _, o := range optional {
+		if o.value != nil {
+			attrs = append(attrs, Attribute{Key: o.key, Value: *o.value})
+		}
+	}
+
+	if maxTokens > 0 {
+		attrs = append(attrs, Attribute{Key: AttributeGenAIRequestMaxTokens, Value: maxTokens})
+	}
+
+	if len(stop) > 0 {
+		attrs = append(attrs, Attribute{Key: AttributeGenAIRequestStopSequences, Value: stop})
+	}
+
+	return attrs
+}
+
+func responseAttributes(body []byte) []Attribute {
+	var resp struct {
+		ID      string `json:"id"`
+		Model   string `json:"model"`
+		Choices []struct {
+			FinishReason string `json:"finish_reason"`
+		} `json:"choices"`
+		Usage *Usage `json:"usage"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil
+	}
+
+	var attrs []Attribute
+
+	if resp.ID != "" {
+		attrs = append(attrs, Attribute{Key: AttributeGenAIResponseID, Value: resp.ID})
+	}
+
+	if resp.Model != "" {
+		attrs = append(attrs, Attribute{Key: AttributeGenAIResponseModel, Value: resp.Model})
+	}
+
+	if len(resp.Choices) > 0 {
+		finishReasons := make([]string, 0, len(resp.Choices))
+		for _, choice := range resp.Choices {
+			finishReasons = append(finishReasons, choice.FinishReason)
+		}
+		attrs = append(attrs, Attribute{Key: AttributeGenAIResponseFinishReasons, Value: finishReasons})
+	}
+
+	if resp.Usage != nil {
+		attrs = append(attrs,
+			Attribute{Key: AttributeGenAIUsageInputTokens, Value: resp.Usage.PromptTokens},
+			Attribute{Key: AttributeGenAIUsageOutputTokens, Value: resp.Usage.CompletionTokens},
+		)
+	}
+
+	return attrs
+}
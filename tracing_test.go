@@ -0,0 +1,83 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type recordingTracer struct {
+	name  string
+	attrs map[string]any
+	ended bool
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	t.name = name
+	t.attrs = make(map[string]any)
+	t.SetAttributes(attrs...)
+	return ctx, t
+}
+
+func (t *recordingTracer) SetAttributes(attrs ...Attribute) {
+	for _, attr := range attrs {
+		t.attrs[attr.Key] = attr.Value
+	}
+}
+
+func (t *recordingTracer) RecordError(error) {}
+
+func (t *recordingTracer) End() {
+	t.ended = true
+}
+
+func TestClient_WithTracer(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id":"chatcmpl-123","model":"gpt-4-0613","choices":[{"finish_reason":"stop"}],` +
+			`"usage":{"prompt_tokens":5,"completion_tokens":7,"total_tokens":12}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	tracer := new(recordingTracer)
+
+	client := NewClient("test", WithBaseURL(server.URL), WithTracer(tracer))
+
+	_, err := client.ChatCompletion(context.Background(), ChatCompletionRequest{
+		Model:       "gpt-4",
+		Temperature: Float(0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !tracer.ended {
+		t.Fatal("expected span to be ended")
+	}
+
+	if got, want := tracer.name, "chat gpt-4"; got != want {
+		t.Fatalf("expected span name to be %q, got %q", want, got)
+	}
+
+	want := map[string]any{
+		AttributeGenAISystem:                "openai",
+		AttributeGenAIOperationName:         "chat",
+		AttributeGenAIRequestModel:          "gpt-4",
+		AttributeGenAIRequestTemperature:    0.0,
+		AttributeGenAIResponseID:            "chatcmpl-123",
+		AttributeGenAIResponseModel:         "gpt-4-0613",
+		AttributeGenAIResponseFinishReasons: []string{"stop"},
+		AttributeGenAIUsageInputTokens:      5,
+		AttributeGenAIUsageOutputTokens:     7,
+		AttributeHTTPResponseStatusCode:     http.StatusOK,
+	}
+
+	for key, value := range want {
+		if got := tracer.attrs[key]; !reflect.DeepEqual(got, value) {
+			t.Errorf("expected attribute %s to be %v, got %v", key, value, got)
+		}
+	}
+}
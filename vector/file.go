@@ -0,0 +1,184 @@
+package vector
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+const (
+	fileMagic   = "VIDX"
+	fileVersion = 1
+
+	maxFileDimensions = 1 << 16
+)
+
+// Save writes the records of the index to w in a compact binary format:
+// the vectors are stored as little-endian float32 values, the strings are length-prefixed.
+// HNSW graphs are not saved but rebuilt by Load.
+func (i *Index) Save(w io.Writer) error {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	bw := bufio.NewWriter(w)
+
+	header := make([]byte, 0, 14)
+	header = append(header, fileMagic...)
+	header = append(header, fileVersion, byte(i.metric))
+	header = binary.LittleEndian.AppendUint32(header, uint32(i.dimensions))
+	header = binary.LittleEndian.AppendUint32(header, uint32(len(i.ids)))
+
+	if _, err := bw.Write(header); err != nil {
+		return err
+	}
+
+	buf := make([]byte, 0, 4*i.dimensions)
+
+	for node, record := range i.records {
+		if i.deleted[node] {
+			continue
+		}
+
+		buf = appendString(buf[:0], record.ID)
+		buf = binary.AppendUvarint(buf, uint64(len(record.Metadata)))
+
+		keys := make([]string, 0, len(record.Metadata))
+		for key := range record.Metadata {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			buf = appendString(buf, key)
+			buf = appendString(buf, record.Metadata[key])
+		}
+
+		for _, value := range record.Vector {
+			buf = binary.LittleEndian.AppendUint32(buf, math.Float32bits(value))
+		}
+
+		if _, err := bw.Write(buf); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// Load reads an index written by Save. Its metric is restored from the data.
+func Load(r io.Reader, options ...IndexOption) (*Index, error) {
+	br := bufio.NewReader(r)
+
+	header := make([]byte, 14)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, fmt.Errorf("vector: invalid index header: %w", err)
+	}
+
+	if string(header[:4]) != fileMagic {
+		return nil, errors.New("vector: not an index file")
+	}
+
+	if header[4] != fileVersion {
+		return nil, fmt.Errorf("vector: unsupported index version %d", header[4])
+	}
+
+	index := NewIndex(options...)
+	index.metric = Metric(header[5])
+
+	dimensions := int(binary.LittleEndian.Uint32(header[6:]))
+	count := int(binary.LittleEndian.Uint32(header[10:]))
+
+	if dimensions > maxFileDimensions {
+		return nil, fmt.Errorf("vector: invalid index of %d dimensions", dimensions)
+	}
+
+	vector := make([]byte, 4*dimensions)
+
+	for n := 0; n < count; n++ {
+		var (
+			record Record
+			err    error
+		)
+
+		if record.ID, err = readString(br); err != nil {
+			return nil, fmt.Errorf("vector: invalid record %d: %w", n, err)
+		}
+
+		entries, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, fmt.Errorf("vector: invalid record %d: %w", n, err)
+		}
+
+		if entries > 0 {
+			record.Metadata = make(map[string]string)
+		}
+
+		for e := uint64(0); e < entries; e++ {
+			key, err := readString(br)
+			if err != nil {
+				return nil, fmt.Errorf("vector: invalid record %d: %w", n, err)
+			}
+
+			if record.Metadata[key], err = readString(br); err != nil {
+				return nil, fmt.Errorf("vector: invalid record %d: %w", n, err)
+			}
+		}
+
+		if _, err = io.ReadFull(br, vector); err != nil {
+			return nil, fmt.Errorf("vector: invalid record %d: %w", n, err)
+		}
+
+		record.Vector = make([]float32, dimensions)
+		for d := range record.Vector {
+			record.Vector[d] = math.Float32frombits(binary.LittleEndian.Uint32(vector[4*d:]))
+		}
+
+		if err = index.Add(record); err != nil {
+			return nil, err
+		}
+	}
+
+	return index, nil
+}
+
+func appendString(buf []byte, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+// readString reads a length-prefixed string, refusing lengths beyond the remaining data.
+func readString(br *bufio.Reader) (string, error) {
+	length, err := binary.ReadUvarint(br)
+	if err != nil {
+		return "", err
+	}
+
+	if length > math.MaxInt32 {
+		return "", fmt.Errorf("string of %d bytes", length)
+	}
+
+	data, err := readN(br, int(length))
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// readN reads n bytes without allocating them upfront, so corrupted lengths fail on EOF instead of exhausting memory.
+func readN(r io.Reader, n int) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, int64(n)))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) != n {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	return data, nil
+}
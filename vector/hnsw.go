@@ -0,0 +1,246 @@
+package vector
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// hnsw is a hierarchical navigable small world graph, see https://arxiv.org/abs/1603.09320.
+// Nodes are the positions of the records in the index.
+type hnsw struct {
+	m              int
+	mMax0          int
+	efConstruction int
+	efSearch       int
+	levelFactor    float64
+	rand           *rand.Rand
+
+	nodes    []hnswNode
+	entry    int
+	maxLevel int
+}
+
+type hnswNode struct {
+	// neighbors holds the neighbours of the node on each of its layers, from the bottom one.
+	neighbors [][]int
+}
+
+func newHNSW(config HNSWConfig) *hnsw {
+	if config.M <= 1 {
+		config.M = 16
+	}
+	if config.EfConstruction <= 0 {
+		config.EfConstruction = 200
+	}
+	if config.EfSearch <= 0 {
+		config.EfSearch = 64
+	}
+
+	return &hnsw{
+		m:              config.M,
+		mMax0:          2 * config.M,
+		efConstruction: config.EfConstruction,
+		efSearch:       config.EfSearch,
+		levelFactor:    1 / math.Log(float64(config.M)),
+		// A fixed seed makes graphs, and so approximate results, reproducible.
+		rand:  rand.New(rand.NewSource(1)),
+		entry: -1,
+	}
+}
+
+// empty returns a graph with the same configuration and no nodes.
+func (h *hnsw) empty() *hnsw {
+	return newHNSW(HNSWConfig{M: h.m, EfConstruction: h.efConstruction, EfSearch: h.efSearch})
+}
+
+// add adds the next node to the graph with a random level. It is not reachable until it is linked.
+func (h *hnsw) add() {
+	level := int(-math.Log(1-h.rand.Float64()) * h.levelFactor)
+
+	h.nodes = append(h.nodes, hnswNode{neighbors: make([][]int, level+1)})
+}
+
+// connections returns the neighbours of the node on the layers it shares with the graph, from the bottom one.
+// It only reads the graph, so it can run alongside searches.
+func (h *hnsw) connections(node int, vectorOf func(int) []float32, metric Metric) [][]int {
+	if h.entry < 0 {
+		return nil
+	}
+
+	level := len(h.nodes[node].neighbors) - 1
+	query := vectorOf(node)
+	entries := []candidate{{node: h.entry, distance: metric.distance(query, vectorOf(h.entry))}}
+
+	for layer := h.maxLevel; layer > level; layer-- {
+		entries = h.searchLayer(query, entries, 1, layer, vectorOf, metric)[:1]
+	}
+
+	connections := make([][]int, min(level, h.maxLevel)+1)
+
+	for layer := len(connections) - 1; layer >= 0; layer-- {
+		found := h.searchLayer(query, entries, h.efConstruction, layer, vectorOf, metric)
+
+		neighbors := make([]int, 0, h.m)
+		for _, c := range found[:min(h.m, len(found))] {
+			neighbors = append(neighbors, c.node)
+		}
+		connections[layer] = neighbors
+
+		entries = found
+	}
+
+	return connections
+}
+
+// link connects the node to its neighbours, found by connections, pruning their links beyond the maximum.
+func (h *hnsw) link(node int, connections [][]int, vectorOf func(int) []float32, metric Metric) {
+	for layer, neighbors := range connections {
+		h.nodes[node].neighbors[layer] = neighbors
+
+		maxNeighbors := h.m
+		if layer == 0 {
+			maxNeighbors = h.mMax0
+		}
+
+		for _, neighbor := range neighbors {
+			links := append(h.nodes[neighbor].neighbors[layer], node)
+			if len(links) > maxNeighbors {
+				links = h.closest(vectorOf(neighbor), links, maxNeighbors, vectorOf, metric)
+			}
+			h.nodes[neighbor].neighbors[layer] = links
+		}
+	}
+
+	if level := len(h.nodes[node].neighbors) - 1; h.entry < 0 || level > h.maxLevel {
+		h.entry, h.maxLevel = node, level
+	}
+}
+
+// search returns up to k nodes closest to the query among the selected ones, best first.
+func (h *hnsw) search(query []float32, k int, vectorOf func(int) []float32, metric Metric, selected func(int) bool) []Match {
+	if h.entry < 0 {
+		return nil
+	}
+
+	entries := []candidate{{node: h.entry, distance: metric.distance(query, vectorOf(h.entry))}}
+
+	for layer := h.maxLevel; layer > 0; layer-- {
+		entries = h.searchLayer(query, entries, 1, layer, vectorOf, metric)[:1]
+	}
+
+	var matches []Match
+
+	for _, c := range h.searchLayer(query, entries, max(h.efSearch, k), 0, vectorOf, metric) {
+		if !selected(c.node) {
+			continue
+		}
+
+		matches = append(matches, Match{Index: c.node, Score: metric.Score(query, vectorOf(c.node))})
+		if len(matches) == k {
+			break
+		}
+	}
+
+	return matches
+}
+
+// searchLayer returns up to ef nodes of the layer closest to the query, closest first,
+// walking the graph from the entry nodes.
+func (h *hnsw) searchLayer(query []float32, entries []candidate, ef, layer int, vectorOf func(int) []float32, metric Metric) []candidate {
+	visited := make(map[int]bool, ef*4)
+	candidates := &candidateHeap{}
+	results := &candidateHeap{farthestFirst: true}
+
+	for _, entry := range entries {
+		visited[entry.node] = true
+		heap.Push(candidates, entry)
+		heap.Push(results, entry)
+	}
+
+	for candidates.Len() > 0 {
+		closest := heap.Pop(candidates).(candidate)
+
+		if results.Len() >= ef && closest.distance > results.items[0].distance {
+			break
+		}
+
+		for _, neighbor := range h.nodes[closest.node].neighbors[layer] {
+			if visited[neighbor] {
+				continue
+			}
+			visited[neighbor] = true
+
+			c := candidate{node: neighbor, distance: metric.distance(query, vectorOf(neighbor))}
+
+			if results.Len() < ef || c.distance < results.items[0].distance {
+				heap.Push(candidates, c)
+				heap.Push(results, c)
+
+				if results.Len() > ef {
+					heap.Pop(results)
+				}
+			}
+		}
+	}
+
+	found := results.items
+	sort.Slice(found, func(i, j int) bool { return found[i].distance < found[j].distance })
+
+	return found
+}
+
+// closest returns the n nodes closest to the vector.
+func (h *hnsw) closest(vector []float32, nodes []int, n int, vectorOf func(int) []float32, metric Metric) []int {
+	candidates := make([]candidate, len(nodes))
+	for i, node := range nodes {
+		candidates[i] = candidate{node: node, distance: metric.distance(vector, vectorOf(node))}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].distance < candidates[j].distance })
+
+	closest := make([]int, n)
+	for i := range closest {
+		closest[i] = candidates[i].node
+	}
+
+	return closest
+}
+
+type candidate struct {
+	node     int
+	distance float32
+}
+
+// candidateHeap keeps the closest candidate on top, or the farthest one if farthestFirst is set.
+type candidateHeap struct {
+	items         []candidate
+	farthestFirst bool
+}
+
+func (h *candidateHeap) Len() int {
+	return len(h.items)
+}
+
+func (h *candidateHeap) Less(i, j int) bool {
+	if h.farthestFirst {
+		return h.items[i].distance > h.items[j].distance
+	}
+
+	return h.items[i].distance < h.items[j].distance
+}
+
+func (h *candidateHeap) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+}
+
+func (h *candidateHeap) Push(x any) {
+	h.items = append(h.items, x.(candidate))
+}
+
+func (h *candidateHeap) Pop() any {
+	last := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return last
+}
@@ -0,0 +1,419 @@
+package vector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrNoEmbedder is returned by the text methods of an Index created without WithEmbedder.
+var ErrNoEmbedder = errors.New("vector: index has no embedder")
+
+// Embedder creates the embeddings of texts, e.g. openai.Embedder.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// Record is a vector stored in an Index.
+type Record struct {
+	ID       string
+	Vector   []float32
+	Metadata map[string]string
+}
+
+// TextRecord is a text to embed and store in an Index.
+type TextRecord struct {
+	ID       string
+	Text     string
+	Metadata map[string]string
+}
+
+// Result is a search result.
+type Result struct {
+	ID       string
+	Score    float32
+	Metadata map[string]string
+}
+
+// Filter selects the records a search may return by their metadata.
+type Filter func(metadata map[string]string) bool
+
+// Equals selects the records whose metadata key has the value.
+func Equals(key, value string) Filter {
+	return func(metadata map[string]string) bool {
+		return metadata[key] == value
+	}
+}
+
+// HNSWConfig configures the approximate search of an Index. Zero values are replaced by the defaults.
+type HNSWConfig struct {
+	// M is the number of neighbours of a node per layer, twice that on the bottom layer. Defaults to 16.
+	M int
+	// EfConstruction is the number of candidates considered when inserting. Defaults to 200.
+	EfConstruction int
+	// EfSearch is the number of candidates considered when searching, at least k. Defaults to 64.
+	EfSearch int
+}
+
+type IndexOption func(*Index)
+
+// WithMetric sets the metric ranking search results. Defaults to Cosine.
+func WithMetric(metric Metric) IndexOption {
+	return func(i *Index) {
+		i.metric = metric
+	}
+}
+
+// WithHNSW makes searches approximate, using a hierarchical navigable small world graph.
+// Searches are then much faster on large indexes, at the cost of slower inserts and possibly missed results.
+func WithHNSW(config HNSWConfig) IndexOption {
+	return func(i *Index) {
+		i.graph = newHNSW(config)
+	}
+}
+
+// WithEmbedder enables the text methods of the Index.
+func WithEmbedder(embedder Embedder) IndexOption {
+	return func(i *Index) {
+		i.embedder = embedder
+	}
+}
+
+// Index is an in-memory vector store supporting exact and approximate nearest-neighbour search.
+// It is safe for concurrent use.
+// Replaced and deleted records are dropped once they make up more than half of the stored ones.
+type Index struct {
+	metric   Metric
+	embedder Embedder
+
+	// writeMu serializes the changes, so they can read the index under mu.RLock without blocking searches.
+	writeMu sync.Mutex
+
+	mu         sync.RWMutex
+	dimensions int
+	records    []Record
+	deleted    []bool
+	// tombstones is the number of replaced and deleted records still stored.
+	tombstones int
+	ids        map[string]int
+	graph      *hnsw
+}
+
+// NewIndex creates an empty Index.
+func NewIndex(options ...IndexOption) *Index {
+	index := &Index{
+		metric: Cosine,
+		ids:    make(map[string]int),
+	}
+
+	for _, option := range options {
+		option(index)
+	}
+
+	return index
+}
+
+// Len returns the number of records.
+func (i *Index) Len() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return len(i.ids)
+}
+
+// Add stores the records, replacing the ones with the same ID.
+// All vectors of an index must have the same length.
+// With HNSW, the neighbours of the records are searched without blocking concurrent searches.
+func (i *Index) Add(records ...Record) error {
+	i.writeMu.Lock()
+	defer i.writeMu.Unlock()
+
+	first, err := i.store(records)
+	if err != nil {
+		return err
+	}
+
+	if i.graph != nil {
+		for node := first; node < first+len(records); node++ {
+			i.link(node)
+		}
+	}
+
+	i.compact()
+
+	return nil
+}
+
+// store validates and stores the records, returning the node of the first one.
+func (i *Index) store(records []Record) (int, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	// The dimensions of an empty index are set by the first record, once the whole batch is valid.
+	dimensions := i.dimensions
+
+	for _, record := range records {
+		if len(record.Vector) == 0 {
+			return 0, fmt.Errorf("vector: record %q has no vector", record.ID)
+		}
+
+		if dimensions == 0 {
+			dimensions = len(record.Vector)
+		}
+
+		if len(record.Vector) != dimensions {
+			return 0, fmt.Errorf("vector: record %q has %d dimensions, expected %d", record.ID, len(record.Vector), dimensions)
+		}
+	}
+
+	i.dimensions = dimensions
+	first := len(i.records)
+
+	for _, record := range records {
+		if previous, ok := i.ids[record.ID]; ok {
+			// Graph nodes cannot be removed, so replaced records are only excluded from results until compacted.
+			i.deleted[previous] = true
+			i.tombstones++
+		}
+
+		i.ids[record.ID] = len(i.records)
+		i.records = append(i.records, record)
+		i.deleted = append(i.deleted, false)
+
+		if i.graph != nil {
+			i.graph.add()
+		}
+	}
+
+	return first, nil
+}
+
+// link connects the node to the graph. Its neighbours are searched under the read lock,
+// which is enough as writeMu keeps the graph from changing meanwhile. i.writeMu must be held.
+func (i *Index) link(node int) {
+	i.mu.RLock()
+	connections := i.graph.connections(node, i.vectorOf, i.metric)
+	i.mu.RUnlock()
+
+	i.mu.Lock()
+	i.graph.link(node, connections, i.vectorOf, i.metric)
+	i.mu.Unlock()
+}
+
+// compact rebuilds the index without the replaced and deleted records once they make up more than half of it.
+// The new records and graph are built without blocking searches and swapped in at the end. i.writeMu must be held.
+func (i *Index) compact() {
+	i.mu.RLock()
+
+	if i.tombstones*2 <= len(i.records) {
+		i.mu.RUnlock()
+		return
+	}
+
+	records := make([]Record, 0, len(i.ids))
+	for node, record := range i.records {
+		if !i.deleted[node] {
+			records = append(records, record)
+		}
+	}
+
+	var graph *hnsw
+	if i.graph != nil {
+		graph = i.graph.empty()
+	}
+
+	i.mu.RUnlock()
+
+	ids := make(map[string]int, len(records))
+	for node, record := range records {
+		ids[record.ID] = node
+	}
+
+	if graph != nil {
+		vectorOf := func(node int) []float32 {
+			return records[node].Vector
+		}
+
+		for node := range records {
+			graph.add()
+			graph.link(node, graph.connections(node, vectorOf, i.metric), vectorOf, i.metric)
+		}
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.records = records
+	i.deleted = make([]bool, len(records))
+	i.tombstones = 0
+	i.ids = ids
+	i.graph = graph
+}
+
+func (i *Index) vectorOf(node int) []float32 {
+	return i.records[node].Vector
+}
+
+// Delete removes the record with the ID, if any.
+func (i *Index) Delete(id string) {
+	i.writeMu.Lock()
+	defer i.writeMu.Unlock()
+
+	i.mu.Lock()
+
+	if node, ok := i.ids[id]; ok {
+		i.deleted[node] = true
+		i.tombstones++
+		delete(i.ids, id)
+	}
+
+	i.mu.Unlock()
+
+	i.compact()
+}
+
+// Get returns the record with the ID.
+func (i *Index) Get(id string) (Record, bool) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	node, ok := i.ids[id]
+	if !ok {
+		return Record{}, false
+	}
+
+	return i.records[node], true
+}
+
+type searchOptions struct {
+	filter Filter
+	exact  bool
+}
+
+type SearchOption func(*searchOptions)
+
+// WithFilter restricts the results to the records selected by the filter.
+func WithFilter(filter Filter) SearchOption {
+	return func(o *searchOptions) {
+		o.filter = filter
+	}
+}
+
+// WithExactSearch compares the query with every record even if the index uses HNSW.
+func WithExactSearch() SearchOption {
+	return func(o *searchOptions) {
+		o.exact = true
+	}
+}
+
+// Search returns the k records most similar to the query, best first.
+// With HNSW and a filter selecting few records, the approximate search may find fewer than k of them,
+// in which case it falls back to an exact one.
+func (i *Index) Search(query []float32, k int, options ...SearchOption) ([]Result, error) {
+	var opts searchOptions
+	for _, option := range options {
+		option(&opts)
+	}
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	if k <= 0 || len(i.ids) == 0 {
+		return nil, nil
+	}
+
+	if len(query) != i.dimensions {
+		return nil, fmt.Errorf("vector: query has %d dimensions, expected %d", len(query), i.dimensions)
+	}
+
+	var matches []Match
+
+	if i.graph != nil && !opts.exact {
+		matches = i.graph.search(query, k, i.vectorOf, i.metric, func(node int) bool {
+			return i.selected(node, opts.filter)
+		})
+	}
+
+	if len(matches) < k && (i.graph == nil || opts.exact || opts.filter != nil) {
+		matches = i.exactSearch(query, k, opts.filter)
+	}
+
+	results := make([]Result, len(matches))
+	for j, match := range matches {
+		record := i.records[match.Index]
+		results[j] = Result{ID: record.ID, Score: match.Score, Metadata: record.Metadata}
+	}
+
+	return results, nil
+}
+
+func (i *Index) exactSearch(query []float32, k int, filter Filter) []Match {
+	h := &matchHeap{metric: i.metric}
+
+	for node, record := range i.records {
+		if !i.selected(node, filter) {
+			continue
+		}
+
+		score := i.metric.Score(query, record.Vector)
+
+		if h.Len() < k {
+			h.push(Match{Index: node, Score: score})
+		} else if i.metric.better(score, h.matches[0].Score) {
+			h.replaceWorst(Match{Index: node, Score: score})
+		}
+	}
+
+	return h.sorted()
+}
+
+func (i *Index) selected(node int, filter Filter) bool {
+	return !i.deleted[node] && (filter == nil || filter(i.records[node].Metadata))
+}
+
+// AddText embeds the texts with the embedder and stores them.
+func (i *Index) AddText(ctx context.Context, records ...TextRecord) error {
+	if i.embedder == nil {
+		return ErrNoEmbedder
+	}
+
+	texts := make([]string, len(records))
+	for j, record := range records {
+		texts[j] = record.Text
+	}
+
+	vectors, err := i.embedder.Embed(ctx, texts)
+	if err != nil {
+		return err
+	}
+
+	if len(vectors) != len(records) {
+		return fmt.Errorf("vector: expected %d embeddings, got %d", len(records), len(vectors))
+	}
+
+	embedded := make([]Record, len(records))
+	for j, record := range records {
+		embedded[j] = Record{ID: record.ID, Vector: vectors[j], Metadata: record.Metadata}
+	}
+
+	return i.Add(embedded...)
+}
+
+// SearchText embeds the query with the embedder and returns the k most similar records.
+func (i *Index) SearchText(ctx context.Context, query string, k int, options ...SearchOption) ([]Result, error) {
+	if i.embedder == nil {
+		return nil, ErrNoEmbedder
+	}
+
+	vectors, err := i.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("vector: expected 1 embedding, got %d", len(vectors))
+	}
+
+	return i.Search(vectors[0], k, options...)
+}
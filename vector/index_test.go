@@ -0,0 +1,312 @@
+package vector
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func randomRecords(n, dimensions int) []Record {
+	r := rand.New(rand.NewSource(42))
+
+	records := make([]Record, n)
+	for i := range records {
+		v := make([]float32, dimensions)
+		for d := range v {
+			v[d] = r.Float32()*2 - 1
+		}
+		Normalize(v)
+
+		records[i] = Record{
+			ID:       fmt.Sprint(i),
+			Vector:   v,
+			Metadata: map[string]string{"parity": fmt.Sprint(i % 2)},
+		}
+	}
+
+	return records
+}
+
+func TestIndex_Search(t *testing.T) {
+	t.Parallel()
+
+	records := randomRecords(1000, 16)
+
+	exact := NewIndex()
+	approximate := NewIndex(WithHNSW(HNSWConfig{}))
+
+	for _, index := range []*Index{exact, approximate} {
+		if err := index.Add(records...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var found, total int
+
+	for _, query := range randomRecords(20, 16) {
+		want, err := exact.Search(query.Vector, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := approximate.Search(query.Vector, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ids := make(map[string]bool)
+		for _, result := range got {
+			ids[result.ID] = true
+		}
+
+		for _, result := range want {
+			if ids[result.ID] {
+				found++
+			}
+		}
+		total += len(want)
+	}
+
+	if recall := float64(found) / float64(total); recall < 0.9 {
+		t.Fatalf("expected HNSW recall to be at least 0.9, got %v", recall)
+	}
+}
+
+func TestIndex_Filter(t *testing.T) {
+	t.Parallel()
+
+	for _, index := range []*Index{NewIndex(), NewIndex(WithHNSW(HNSWConfig{}))} {
+		if err := index.Add(randomRecords(200, 8)...); err != nil {
+			t.Fatal(err)
+		}
+
+		results, err := index.Search(randomRecords(1, 8)[0].Vector, 5, WithFilter(Equals("parity", "1")))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(results) != 5 {
+			t.Fatalf("expected 5 results, got %d", len(results))
+		}
+
+		for _, result := range results {
+			if result.Metadata["parity"] != "1" {
+				t.Fatalf("expected only odd records, got %+v", result)
+			}
+		}
+	}
+}
+
+func TestIndex_AddDelete(t *testing.T) {
+	t.Parallel()
+
+	index := NewIndex(WithMetric(Euclidean), WithHNSW(HNSWConfig{}))
+
+	if err := index.Add(Record{ID: "a", Vector: []float32{0, 0}}, Record{ID: "b", Vector: []float32{5, 5}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := index.Add(Record{ID: "b", Vector: []float32{1, 1}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := index.Add(Record{ID: "c", Vector: []float32{1}}); err == nil {
+		t.Fatal("expected an error for a vector of other dimensions")
+	}
+
+	results, err := index.Search([]float32{1, 1}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 1 || results[0].ID != "b" || results[0].Score != 0 {
+		t.Fatalf("expected the replaced record b at distance 0, got %+v", results)
+	}
+
+	index.Delete("b")
+
+	if index.Len() != 1 {
+		t.Fatalf("expected 1 record, got %d", index.Len())
+	}
+
+	if results, _ = index.Search([]float32{1, 1}, 2); len(results) != 1 || results[0].ID != "a" {
+		t.Fatalf("expected only record a, got %+v", results)
+	}
+}
+
+func TestIndex_AddInvalidBatch(t *testing.T) {
+	t.Parallel()
+
+	index := NewIndex()
+
+	if err := index.Add(Record{ID: "a", Vector: []float32{1, 0}}, Record{ID: "b", Vector: []float32{1}}); err == nil {
+		t.Fatal("expected an error for a batch of vectors of different dimensions")
+	}
+
+	// The invalid batch must leave the index empty, so it accepts vectors of any dimensions.
+	if err := index.Add(Record{ID: "c", Vector: []float32{1, 0, 0}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if index.Len() != 1 {
+		t.Fatalf("expected 1 record, got %d", index.Len())
+	}
+}
+
+func TestIndex_Compact(t *testing.T) {
+	t.Parallel()
+
+	index := NewIndex(WithHNSW(HNSWConfig{}))
+	records := randomRecords(50, 8)
+
+	// Re-adding the same records, as rag.AddDocuments does, must not grow the index forever.
+	for round := 0; round < 10; round++ {
+		if err := index.Add(records...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, record := range records[:10] {
+		index.Delete(record.ID)
+	}
+
+	if got := len(index.records); got > 2*index.Len() {
+		t.Fatalf("expected at most %d stored records, got %d", 2*index.Len(), got)
+	}
+
+	for _, record := range records[10:] {
+		results, err := index.Search(record.Vector, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(results) != 1 || results[0].ID != record.ID {
+			t.Fatalf("expected record %s, got %+v", record.ID, results)
+		}
+	}
+}
+
+func TestIndex_SaveLoad(t *testing.T) {
+	t.Parallel()
+
+	index := NewIndex(WithMetric(DotProduct))
+	records := randomRecords(50, 4)
+
+	if err := index.Add(records...); err != nil {
+		t.Fatal(err)
+	}
+	index.Delete("0")
+
+	var buf bytes.Buffer
+	if err := index.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(&buf, WithHNSW(HNSWConfig{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.Len() != 49 || loaded.metric != DotProduct {
+		t.Fatalf("expected 49 records and the dot product metric, got %d and %d", loaded.Len(), loaded.metric)
+	}
+
+	got, ok := loaded.Get("7")
+	if !ok || fmt.Sprint(got) != fmt.Sprint(records[7]) {
+		t.Fatalf("expected record %+v, got %+v", records[7], got)
+	}
+
+	if _, err = Load(strings.NewReader("VIDX")); err == nil {
+		t.Fatal("expected an error for a truncated file")
+	}
+}
+
+type embedderFunc func(ctx context.Context, texts []string) ([][]float32, error)
+
+func (f embedderFunc) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return f(ctx, texts)
+}
+
+func TestIndex_Text(t *testing.T) {
+	t.Parallel()
+
+	// The embedding of a text counts its a, b and c letters.
+	embedder := embedderFunc(func(_ context.Context, texts []string) ([][]float32, error) {
+		vectors := make([][]float32, len(texts))
+		for i, text := range texts {
+			vectors[i] = []float32{
+				float32(strings.Count(text, "a")),
+				float32(strings.Count(text, "b")),
+				float32(strings.Count(text, "c")),
+			}
+		}
+		return vectors, nil
+	})
+
+	if _, err := NewIndex().SearchText(context.Background(), "a", 1); err != ErrNoEmbedder {
+		t.Fatalf("expected ErrNoEmbedder, got %v", err)
+	}
+
+	index := NewIndex(WithEmbedder(embedder))
+
+	err := index.AddText(context.Background(),
+		TextRecord{ID: "a", Text: "aaa"},
+		TextRecord{ID: "b", Text: "bbb"},
+		TextRecord{ID: "c", Text: "ccc"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := index.SearchText(context.Background(), "bb", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 1 || results[0].ID != "b" {
+		t.Fatalf("expected record b, got %+v", results)
+	}
+}
+
+func TestIndex_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	index := NewIndex(WithHNSW(HNSWConfig{}))
+	records := randomRecords(200, 8)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+
+		go func(records []Record) {
+			defer wg.Done()
+
+			for _, record := range records {
+				if err := index.Add(record); err != nil {
+					t.Error(err)
+				}
+			}
+		}(records[i*50 : (i+1)*50])
+
+		go func(query []float32) {
+			defer wg.Done()
+
+			for j := 0; j < 50; j++ {
+				if _, err := index.Search(query, 3); err != nil {
+					t.Error(err)
+				}
+			}
+		}(records[i].Vector)
+	}
+
+	wg.Wait()
+
+	if index.Len() != 200 {
+		t.Fatalf("expected 200 records, got %d", index.Len())
+	}
+}
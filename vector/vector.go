@@ -0,0 +1,228 @@
+// Package vector provides similarity functions and search over float32 embedding vectors.
+//
+// Functions taking two vectors require them to have the same length and panic otherwise.
+// Loops are unrolled with independent accumulators so the compiler can eliminate bounds checks
+// and the CPU can pipeline the multiplications.
+package vector
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Metric is a measure of the similarity of two vectors.
+type Metric int
+
+const (
+	// Cosine is the cosine of the angle between the vectors, from -1 to 1.
+	Cosine Metric = iota
+	// DotProduct equals Cosine for normalized vectors, which OpenAI embeddings are, and is cheaper.
+	DotProduct
+	// Euclidean is the distance between the vectors. Lower scores are more similar.
+	Euclidean
+)
+
+// Score returns the score of a and b under the metric.
+func (m Metric) Score(a, b []float32) float32 {
+	switch m {
+	case DotProduct:
+		return Dot(a, b)
+	case Euclidean:
+		return Distance(a, b)
+	default:
+		return CosineSimilarity(a, b)
+	}
+}
+
+// distance returns a value of a and b which is lower for more similar vectors.
+func (m Metric) distance(a, b []float32) float32 {
+	switch m {
+	case DotProduct:
+		return -Dot(a, b)
+	case Euclidean:
+		return SquaredDistance(a, b)
+	default:
+		return 1 - CosineSimilarity(a, b)
+	}
+}
+
+// better reports whether score x ranks before score y under the metric.
+func (m Metric) better(x, y float32) bool {
+	if m == Euclidean {
+		return x < y
+	}
+
+	return x > y
+}
+
+// checkLengths panics if a and b have different lengths.
+func checkLengths(a, b []float32) {
+	if len(a) != len(b) {
+		panic(fmt.Sprintf("vector: length mismatch: %d and %d", len(a), len(b)))
+	}
+}
+
+// Dot returns the dot product of a and b.
+func Dot(a, b []float32) float32 {
+	checkLengths(a, b)
+	b = b[:len(a)]
+
+	var s0, s1, s2, s3 float32
+
+	i := 0
+	for ; i <= len(a)-4; i += 4 {
+		s0 += a[i] * b[i]
+		s1 += a[i+1] * b[i+1]
+		s2 += a[i+2] * b[i+2]
+		s3 += a[i+3] * b[i+3]
+	}
+
+	for ; i < len(a); i++ {
+		s0 += a[i] * b[i]
+	}
+
+	return s0 + s1 + s2 + s3
+}
+
+// Norm returns the Euclidean length of v.
+func Norm(v []float32) float32 {
+	return float32(math.Sqrt(float64(Dot(v, v))))
+}
+
+// Normalize scales v in place to unit length. Zero vectors are left as is.
+func Normalize(v []float32) {
+	norm := Norm(v)
+	if norm == 0 {
+		return
+	}
+
+	inverse := 1 / norm
+	for i := range v {
+		v[i] *= inverse
+	}
+}
+
+// CosineSimilarity returns the cosine of the angle between a and b, or 0 if one of them is a zero vector.
+func CosineSimilarity(a, b []float32) float32 {
+	checkLengths(a, b)
+	b = b[:len(a)]
+
+	var dot, aa, bb float32
+
+	for i := range a {
+		dot += a[i] * b[i]
+		aa += a[i] * a[i]
+		bb += b[i] * b[i]
+	}
+
+	if aa == 0 || bb == 0 {
+		return 0
+	}
+
+	return dot / float32(math.Sqrt(float64(aa)*float64(bb)))
+}
+
+// SquaredDistance returns the squared Euclidean distance between a and b.
+// It ranks vectors like Distance without the square root.
+func SquaredDistance(a, b []float32) float32 {
+	checkLengths(a, b)
+	b = b[:len(a)]
+
+	var s0, s1, s2, s3 float32
+
+	i := 0
+	for ; i <= len(a)-4; i += 4 {
+		d0, d1, d2, d3 := a[i]-b[i], a[i+1]-b[i+1], a[i+2]-b[i+2], a[i+3]-b[i+3]
+		s0 += d0 * d0
+		s1 += d1 * d1
+		s2 += d2 * d2
+		s3 += d3 * d3
+	}
+
+	for ; i < len(a); i++ {
+		d := a[i] - b[i]
+		s0 += d * d
+	}
+
+	return s0 + s1 + s2 + s3
+}
+
+// Distance returns the Euclidean distance between a and b.
+func Distance(a, b []float32) float32 {
+	return float32(math.Sqrt(float64(SquaredDistance(a, b))))
+}
+
+// Match is a search result.
+type Match struct {
+	// Index of the vector in the searched slice.
+	Index int
+	Score float32
+}
+
+// TopK returns the k vectors most similar to the query under the metric, best first.
+func TopK(query []float32, vectors [][]float32, k int, metric Metric) []Match {
+	if k <= 0 {
+		return nil
+	}
+
+	h := &matchHeap{metric: metric}
+
+	for i, v := range vectors {
+		score := metric.Score(query, v)
+
+		if h.Len() < k {
+			h.push(Match{Index: i, Score: score})
+		} else if metric.better(score, h.matches[0].Score) {
+			h.replaceWorst(Match{Index: i, Score: score})
+		}
+	}
+
+	return h.sorted()
+}
+
+// matchHeap keeps the worst match on top, so it is the one replaced by a better one.
+type matchHeap struct {
+	metric  Metric
+	matches []Match
+}
+
+func (h *matchHeap) Len() int {
+	return len(h.matches)
+}
+
+func (h *matchHeap) Less(i, j int) bool {
+	return h.metric.better(h.matches[j].Score, h.matches[i].Score)
+}
+
+func (h *matchHeap) Swap(i, j int) {
+	h.matches[i], h.matches[j] = h.matches[j], h.matches[i]
+}
+
+func (h *matchHeap) Push(x any) {
+	h.matches = append(h.matches, x.(Match))
+}
+
+func (h *matchHeap) Pop() any {
+	last := h.matches[len(h.matches)-1]
+	h.matches = h.matches[:len(h.matches)-1]
+	return last
+}
+
+func (h *matchHeap) push(match Match) {
+	heap.Push(h, match)
+}
+
+func (h *matchHeap) replaceWorst(match Match) {
+	h.matches[0] = match
+	heap.Fix(h, 0)
+}
+
+// sorted returns the matches best first.
+func (h *matchHeap) sorted() []Match {
+	matches := h.matches
+	sort.Slice(matches, func(i, j int) bool { return h.metric.better(matches[i].Score, matches[j].Score) })
+
+	return matches
+}
@@ -0,0 +1,123 @@
+package vector
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFunctions(t *testing.T) {
+	t.Parallel()
+
+	a := []float32{1, 2, 3, 4, 5}
+	b := []float32{5, 4, 3, 2, 1}
+
+	tests := []struct {
+		name string
+		got  float32
+		want float32
+	}{
+		{name: "dot", got: Dot(a, b), want: 35},
+		{name: "norm", got: Norm([]float32{3, 4}), want: 5},
+		{name: "cosine", got: CosineSimilarity(a, b), want: 35.0 / 55},
+		{name: "cosine of zero vector", got: CosineSimilarity(a, make([]float32, 5)), want: 0},
+		{name: "squared distance", got: SquaredDistance(a, b), want: 40},
+		{name: "distance", got: Distance([]float32{0, 0}, []float32{3, 4}), want: 5},
+	}
+
+	for _, tt := range tests {
+		if math.Abs(float64(tt.got-tt.want)) > 1e-6 {
+			t.Errorf("expected %s to be %v, got %v", tt.name, tt.want, tt.got)
+		}
+	}
+}
+
+func TestFunctions_LengthMismatch(t *testing.T) {
+	t.Parallel()
+
+	functions := map[string]func(a, b []float32) float32{
+		"dot":              Dot,
+		"cosine":           CosineSimilarity,
+		"squared distance": SquaredDistance,
+	}
+
+	for name, f := range functions {
+		name, f := name, f
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			defer func() {
+				if recover() == nil {
+					t.Fatal("expected a panic")
+				}
+			}()
+
+			f([]float32{1, 2}, []float32{1, 2, 3})
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+
+	v := []float32{3, 0, 4}
+	Normalize(v)
+
+	if want := []float32{0.6, 0, 0.8}; math.Abs(float64(v[0]-want[0])) > 1e-6 || math.Abs(float64(v[2]-want[2])) > 1e-6 {
+		t.Fatalf("expected normalized vector to be %v, got %v", want, v)
+	}
+
+	zero := []float32{0, 0}
+	Normalize(zero)
+
+	if zero[0] != 0 || zero[1] != 0 {
+		t.Fatalf("expected zero vector to be left as is, got %v", zero)
+	}
+}
+
+func TestTopK(t *testing.T) {
+	t.Parallel()
+
+	vectors := [][]float32{{1, 0}, {0, 1}, {0.9, 0.1}, {-1, 0}, {0.7, 0.7}}
+	query := []float32{1, 0}
+
+	tests := []struct {
+		metric Metric
+		want   []int
+	}{
+		{metric: Cosine, want: []int{0, 2, 4}},
+		{metric: DotProduct, want: []int{0, 2, 4}},
+		{metric: Euclidean, want: []int{0, 2, 4}},
+	}
+
+	for _, tt := range tests {
+		matches := TopK(query, vectors, 3, tt.metric)
+
+		if len(matches) != len(tt.want) {
+			t.Fatalf("expected %d matches, got %d", len(tt.want), len(matches))
+		}
+
+		for i, match := range matches {
+			if match.Index != tt.want[i] {
+				t.Fatalf("expected metric %d match %d to be vector %d, got %+v", tt.metric, i, tt.want[i], matches)
+			}
+		}
+	}
+
+	if matches := TopK(query, vectors, 10, Cosine); len(matches) != len(vectors) {
+		t.Fatalf("expected all %d vectors, got %d", len(vectors), len(matches))
+	}
+}
+
+func BenchmarkDot(b *testing.B) {
+	x, y := make([]float32, 1536), make([]float32, 1536)
+	for i := range x {
+		x[i], y[i] = float32(i), float32(len(y)-i)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		Dot(x, y)
+	}
+}